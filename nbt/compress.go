@@ -0,0 +1,74 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// CompressionScheme selects the compression applied by WriteCompressed.
+type CompressionScheme byte
+
+const (
+	CompressionNone CompressionScheme = iota
+	CompressionGzip
+	CompressionZlib
+)
+
+// ReadCompressed reads a named tag from r, transparently decompressing it
+// if it is gzip- or zlib-compressed, as is the case for Minecraft's
+// player and level files. It returns the decoded tag and its name.
+func ReadCompressed(r io.Reader) (Tag, string, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return Tag{}, "", err
+	}
+
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return Tag{}, "", err
+		}
+		defer gz.Close()
+		return ReadNamedTag(gz)
+	case len(magic) >= 1 && magic[0]&0x0f == 0x08:
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return Tag{}, "", err
+		}
+		defer zr.Close()
+		return ReadNamedTag(zr)
+	default:
+		return ReadNamedTag(br)
+	}
+}
+
+// WriteCompressed writes tag as a named tag called name to w, compressed
+// according to scheme.
+func WriteCompressed(w io.Writer, name string, tag Tag, scheme CompressionScheme) error {
+	switch scheme {
+	case CompressionNone:
+		return WriteNamedTag(w, name, tag)
+	case CompressionGzip:
+		gz := gzip.NewWriter(w)
+		if err := WriteNamedTag(gz, name, tag); err != nil {
+			gz.Close()
+			return err
+		}
+		return gz.Close()
+	case CompressionZlib:
+		zw := zlib.NewWriter(w)
+		if err := WriteNamedTag(zw, name, tag); err != nil {
+			zw.Close()
+			return err
+		}
+		return zw.Close()
+	}
+
+	return errors.New("nbt: unknown compression scheme")
+}
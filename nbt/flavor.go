@@ -0,0 +1,253 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Flavor abstracts over the wire encoding of fixed-width numbers and of
+// string/array length prefixes, so that readTagData/writeTagData can be
+// shared between Java Edition's big-endian format and the little-endian
+// and varint-based encodings used by Bedrock Edition.
+type Flavor interface {
+	ReadInt16(r io.Reader) (int16, error)
+	ReadInt32(r io.Reader) (int32, error)
+	ReadInt64(r io.Reader) (int64, error)
+	ReadFloat32(r io.Reader) (float32, error)
+	ReadFloat64(r io.Reader) (float64, error)
+	ReadStringLen(r io.Reader) (int, error)
+	ReadArrayLen(r io.Reader) (int, error)
+
+	WriteInt16(w io.Writer, v int16) error
+	WriteInt32(w io.Writer, v int32) error
+	WriteInt64(w io.Writer, v int64) error
+	WriteFloat32(w io.Writer, v float32) error
+	WriteFloat64(w io.Writer, v float64) error
+	WriteStringLen(w io.Writer, n int) error
+	WriteArrayLen(w io.Writer, n int) error
+}
+
+// intArrayElemSizer is implemented by flavors whose TAG_Int_Array
+// elements each take a fixed number of bytes on the wire. RawArray and
+// WriteRawArray use it to size the raw byte span they stream; flavors
+// that encode TAG_Int_Array elements at variable width (e.g. as a
+// varint) do not implement it, and RawArray/WriteRawArray refuse
+// TAG_Int_Array for them rather than guess a wrong byte span.
+type intArrayElemSizer interface {
+	intArrayElemSize() int
+}
+
+// fixedWidthFlavor implements the read/write of fixed-width numbers for a
+// given byte order, leaving only string/array length framing to be
+// supplied by the embedding flavor.
+type fixedWidthFlavor struct {
+	order binary.ByteOrder
+}
+
+func (f fixedWidthFlavor) ReadInt16(r io.Reader) (int16, error) {
+	var v int16
+	err := binary.Read(r, f.order, &v)
+	return v, err
+}
+
+func (f fixedWidthFlavor) ReadInt32(r io.Reader) (int32, error) {
+	var v int32
+	err := binary.Read(r, f.order, &v)
+	return v, err
+}
+
+func (f fixedWidthFlavor) ReadInt64(r io.Reader) (int64, error) {
+	var v int64
+	err := binary.Read(r, f.order, &v)
+	return v, err
+}
+
+func (f fixedWidthFlavor) ReadFloat32(r io.Reader) (float32, error) {
+	var v float32
+	err := binary.Read(r, f.order, &v)
+	return v, err
+}
+
+func (f fixedWidthFlavor) ReadFloat64(r io.Reader) (float64, error) {
+	var v float64
+	err := binary.Read(r, f.order, &v)
+	return v, err
+}
+
+func (f fixedWidthFlavor) WriteInt16(w io.Writer, v int16) error {
+	return binary.Write(w, f.order, v)
+}
+
+func (f fixedWidthFlavor) WriteInt32(w io.Writer, v int32) error {
+	return binary.Write(w, f.order, v)
+}
+
+func (f fixedWidthFlavor) WriteInt64(w io.Writer, v int64) error {
+	return binary.Write(w, f.order, v)
+}
+
+func (f fixedWidthFlavor) WriteFloat32(w io.Writer, v float32) error {
+	return binary.Write(w, f.order, v)
+}
+
+func (f fixedWidthFlavor) WriteFloat64(w io.Writer, v float64) error {
+	return binary.Write(w, f.order, v)
+}
+
+// fixedLenFlavor frames string and array lengths as a plain fixed-width
+// integer in the embedding flavor's byte order: an int16 for strings
+// (matching Java Edition) and an int32 for arrays/lists.
+type fixedLenFlavor struct {
+	fixedWidthFlavor
+}
+
+func (f fixedLenFlavor) ReadStringLen(r io.Reader) (int, error) {
+	l, err := f.ReadInt16(r)
+	if err != nil {
+		return 0, err
+	}
+	if l < 0 {
+		return 0, errors.New("nbt: string has negative length")
+	}
+	return int(l), nil
+}
+
+func (f fixedLenFlavor) WriteStringLen(w io.Writer, n int) error {
+	return f.WriteInt16(w, int16(n))
+}
+
+func (f fixedLenFlavor) ReadArrayLen(r io.Reader) (int, error) {
+	l, err := f.ReadInt32(r)
+	if err != nil {
+		return 0, err
+	}
+	if l < 0 {
+		return 0, errors.New("nbt: array has negative length")
+	}
+	return int(l), nil
+}
+
+func (f fixedLenFlavor) WriteArrayLen(w io.Writer, n int) error {
+	return f.WriteInt32(w, int32(n))
+}
+
+// intArrayElemSize reports that TAG_Int_Array elements are a fixed 4
+// bytes each, as used by Java Edition and Bedrock's on-disk format.
+func (f fixedLenFlavor) intArrayElemSize() int {
+	return 4
+}
+
+// JavaBigEndian is the classic Java Edition NBT encoding: all fixed-width
+// values and length prefixes are big-endian.
+var JavaBigEndian Flavor = fixedLenFlavor{fixedWidthFlavor{binary.BigEndian}}
+
+// BedrockLittleEndian is the on-disk Bedrock Edition NBT encoding: the
+// same framing as Java Edition, but little-endian throughout.
+var BedrockLittleEndian Flavor = fixedLenFlavor{fixedWidthFlavor{binary.LittleEndian}}
+
+// networkFlavor is Bedrock Edition's "network" NBT encoding: Short,
+// Float and Double remain fixed-width little-endian, but string/array
+// lengths and TAG_Int/TAG_Long payloads are varint-encoded.
+type networkFlavor struct {
+	fixedWidthFlavor
+}
+
+// BedrockNetwork is Bedrock Edition's network NBT encoding, as used in
+// the game's wire protocol: string and array lengths are unsigned LEB128
+// varints, and TAG_Int/TAG_Long payloads are zigzag varints.
+var BedrockNetwork Flavor = networkFlavor{fixedWidthFlavor{binary.LittleEndian}}
+
+func (f networkFlavor) ReadInt32(r io.Reader) (int32, error) {
+	u, err := readUvarint(r, 5)
+	return int32(zigzagDecode(u)), err
+}
+
+func (f networkFlavor) WriteInt32(w io.Writer, v int32) error {
+	return writeUvarint(w, zigzagEncode32(v))
+}
+
+func (f networkFlavor) ReadInt64(r io.Reader) (int64, error) {
+	u, err := readUvarint(r, 10)
+	return zigzagDecode(u), err
+}
+
+func (f networkFlavor) WriteInt64(w io.Writer, v int64) error {
+	return writeUvarint(w, zigzagEncode64(v))
+}
+
+func (f networkFlavor) ReadStringLen(r io.Reader) (int, error) {
+	u, err := readUvarint(r, 5)
+	if err != nil {
+		return 0, err
+	}
+	return int(u), nil
+}
+
+func (f networkFlavor) WriteStringLen(w io.Writer, n int) error {
+	return writeUvarint(w, uint64(n))
+}
+
+func (f networkFlavor) ReadArrayLen(r io.Reader) (int, error) {
+	u, err := readUvarint(r, 5)
+	if err != nil {
+		return 0, err
+	}
+	return int(u), nil
+}
+
+func (f networkFlavor) WriteArrayLen(w io.Writer, n int) error {
+	return writeUvarint(w, uint64(n))
+}
+
+// readUvarint reads an unsigned LEB128 varint, rejecting encodings that
+// would need more than maxBytes bytes (5 for 32-bit values, 10 for
+// 64-bit values, matching Bedrock's protocol limits).
+func readUvarint(r io.Reader, maxBytes int) (uint64, error) {
+	var result uint64
+	var shift uint
+	buf := make([]byte, 1)
+
+	for i := 0; i < maxBytes; i++ {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return 0, err
+		}
+		b := buf[0]
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+		shift += 7
+	}
+
+	return 0, errors.New("nbt: varint is too long")
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if v == 0 {
+			break
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func zigzagEncode32(v int32) uint64 {
+	return uint64(uint32((v << 1) ^ (v >> 31)))
+}
+
+func zigzagEncode64(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func zigzagDecode(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
@@ -0,0 +1,75 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func flavorRoundtrip(t *testing.T, flavor Flavor) {
+	t.Helper()
+
+	tag := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"short":  Tag{Type: TAG_Short, Payload: int16(-1234)},
+		"int":    Tag{Type: TAG_Int, Payload: int32(-123456789)},
+		"long":   Tag{Type: TAG_Long, Payload: int64(-1234567890123)},
+		"double": Tag{Type: TAG_Double, Payload: 1.5},
+		"str":    Tag{Type: TAG_String, Payload: "hello"},
+		"ints":   Tag{Type: TAG_Int_Array, Payload: []int32{1, -2, 3}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteNamedTagFlavor(&buf, "root", tag, flavor); err != nil {
+		t.Fatalf("WriteNamedTagFlavor: %v", err)
+	}
+
+	got, name, err := ReadNamedTagFlavor(bytes.NewReader(buf.Bytes()), flavor)
+	if err != nil {
+		t.Fatalf("ReadNamedTagFlavor: %v", err)
+	}
+	if name != "root" {
+		t.Errorf("name = %q, want %q", name, "root")
+	}
+
+	comp := got.Payload.(TagCompound)
+	if comp["short"].Payload.(int16) != -1234 {
+		t.Errorf("short = %v", comp["short"].Payload)
+	}
+	if comp["int"].Payload.(int32) != -123456789 {
+		t.Errorf("int = %v", comp["int"].Payload)
+	}
+	if comp["long"].Payload.(int64) != -1234567890123 {
+		t.Errorf("long = %v", comp["long"].Payload)
+	}
+	if comp["double"].Payload.(float64) != 1.5 {
+		t.Errorf("double = %v", comp["double"].Payload)
+	}
+	if comp["str"].Payload.(string) != "hello" {
+		t.Errorf("str = %v", comp["str"].Payload)
+	}
+	ints := comp["ints"].Payload.([]int32)
+	if len(ints) != 3 || ints[1] != -2 {
+		t.Errorf("ints = %v", ints)
+	}
+}
+
+func TestJavaBigEndianRoundtrip(t *testing.T) {
+	flavorRoundtrip(t, JavaBigEndian)
+}
+
+func TestBedrockLittleEndianRoundtrip(t *testing.T) {
+	flavorRoundtrip(t, BedrockLittleEndian)
+}
+
+func TestBedrockNetworkRoundtrip(t *testing.T) {
+	flavorRoundtrip(t, BedrockNetwork)
+}
+
+func TestZigzagVarint(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 2, -2, 123456789, -123456789} {
+		enc := zigzagEncode64(v)
+		dec := zigzagDecode(enc)
+		if dec != v {
+			t.Errorf("zigzag roundtrip of %d = %d", v, dec)
+		}
+	}
+}
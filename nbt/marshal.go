@@ -0,0 +1,477 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Marshal returns the NBT encoding of v, wrapped in a named tag with an
+// empty name. v is typically a pointer to a struct, a struct, or a
+// map[string]interface{}; it must marshal to a TAG_Compound at the top
+// level, matching how Minecraft stores an unnamed root compound.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode("", v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses NBT-encoded data and stores the result in the value
+// pointed to by v. v must be a non-nil pointer.
+func Unmarshal(data []byte, v interface{}) error {
+	_, err := NewDecoder(bytes.NewReader(data)).Decode(v)
+	return err
+}
+
+// Encoder writes the NBT encoding of Go values to an output stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the NBT encoding of v as a named tag called name.
+func (e *Encoder) Encode(name string, v interface{}) error {
+	tag, err := marshalValue(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	return WriteNamedTag(e.w, name, tag)
+}
+
+// Decoder reads and decodes NBT-encoded values from an input stream into
+// Go values.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next named tag and stores it in the value pointed to
+// by v, returning the tag's name. v must be a non-nil pointer.
+func (d *Decoder) Decode(v interface{}) (string, error) {
+	tag, name, err := ReadNamedTag(d.r)
+	if err != nil {
+		return name, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return name, errors.New("nbt: Decode requires a non-nil pointer")
+	}
+
+	return name, unmarshalValue(tag, rv.Elem())
+}
+
+var errOmit = errors.New("nbt: omit")
+
+type fieldTag struct {
+	name      string
+	omitempty bool
+	skip      bool
+}
+
+func parseFieldTag(f reflect.StructField) fieldTag {
+	ft := fieldTag{name: f.Name}
+
+	tag, ok := f.Tag.Lookup("nbt")
+	if !ok {
+		return ft
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		ft.skip = true
+		return ft
+	}
+	if parts[0] != "" {
+		ft.name = parts[0]
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			ft.omitempty = true
+		}
+	}
+	return ft
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// marshalValue converts a Go value into a Tag.
+func marshalValue(v reflect.Value) (Tag, error) {
+	if !v.IsValid() {
+		return Tag{}, errors.New("nbt: cannot marshal invalid value")
+	}
+
+	if v.Type() == reflect.TypeOf(Tag{}) {
+		return v.Interface().(Tag), nil
+	}
+	if v.Type() == reflect.TypeOf(TagList{}) {
+		return Tag{Type: TAG_List, Payload: v.Interface().(TagList)}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return Tag{}, errOmit
+		}
+		return marshalValue(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return Tag{}, errOmit
+		}
+		return marshalValue(v.Elem())
+	case reflect.Bool:
+		var b byte
+		if v.Bool() {
+			b = 1
+		}
+		return Tag{Type: TAG_Byte, Payload: b}, nil
+	case reflect.Int8:
+		return Tag{Type: TAG_Byte, Payload: byte(int8(v.Int()))}, nil
+	case reflect.Uint8:
+		return Tag{Type: TAG_Byte, Payload: byte(v.Uint())}, nil
+	case reflect.Int16:
+		return Tag{Type: TAG_Short, Payload: int16(v.Int())}, nil
+	case reflect.Int, reflect.Int32:
+		return Tag{Type: TAG_Int, Payload: int32(v.Int())}, nil
+	case reflect.Int64:
+		return Tag{Type: TAG_Long, Payload: v.Int()}, nil
+	case reflect.Float32:
+		return Tag{Type: TAG_Float, Payload: float32(v.Float())}, nil
+	case reflect.Float64:
+		return Tag{Type: TAG_Double, Payload: v.Float()}, nil
+	case reflect.String:
+		return Tag{Type: TAG_String, Payload: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		return marshalSliceOrArray(v)
+	case reflect.Map:
+		return marshalMap(v)
+	case reflect.Struct:
+		return marshalStruct(v)
+	}
+
+	return Tag{}, fmt.Errorf("nbt: unsupported type %s", v.Type())
+}
+
+func marshalSliceOrArray(v reflect.Value) (Tag, error) {
+	elemKind := v.Type().Elem().Kind()
+
+	if elemKind == reflect.Uint8 {
+		data := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(data), v)
+		return Tag{Type: TAG_Byte_Array, Payload: data}, nil
+	}
+
+	if elemKind == reflect.Int32 {
+		data := make([]int32, v.Len())
+		for i := range data {
+			data[i] = int32(v.Index(i).Int())
+		}
+		return Tag{Type: TAG_Int_Array, Payload: data}, nil
+	}
+
+	var ltt TagType
+	elems := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		tag, err := marshalValue(v.Index(i))
+		if err == errOmit {
+			continue
+		}
+		if err != nil {
+			return Tag{}, err
+		}
+		if len(elems) == 0 {
+			ltt = tag.Type
+		} else if tag.Type != ltt {
+			return Tag{}, fmt.Errorf("nbt: list elements have mixed tag types %s and %s", ltt, tag.Type)
+		}
+		elems = append(elems, tag.Payload)
+	}
+
+	return Tag{Type: TAG_List, Payload: TagList{Type: ltt, Elems: elems}}, nil
+}
+
+func marshalMap(v reflect.Value) (Tag, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return Tag{}, fmt.Errorf("nbt: unsupported map key type %s", v.Type().Key())
+	}
+
+	comp := make(TagCompound)
+	for _, key := range v.MapKeys() {
+		tag, err := marshalValue(v.MapIndex(key))
+		if err == errOmit {
+			continue
+		}
+		if err != nil {
+			return Tag{}, err
+		}
+		comp[key.String()] = tag
+	}
+	return Tag{Type: TAG_Compound, Payload: comp}, nil
+}
+
+func marshalStruct(v reflect.Value) (Tag, error) {
+	comp := make(TagCompound)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		if f.Anonymous && isStructType(f.Type) {
+			inner, err := marshalStruct(derefStruct(v.Field(i)))
+			if err == errOmit {
+				continue
+			}
+			if err != nil {
+				return Tag{}, err
+			}
+			for name, tag := range inner.Payload.(TagCompound) {
+				comp[name] = tag
+			}
+			continue
+		}
+
+		ft := parseFieldTag(f)
+		if ft.skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if ft.omitempty && isEmptyValue(fv) {
+			continue
+		}
+
+		tag, err := marshalValue(fv)
+		if err == errOmit {
+			continue
+		}
+		if err != nil {
+			return Tag{}, err
+		}
+		comp[ft.name] = tag
+	}
+
+	return Tag{Type: TAG_Compound, Payload: comp}, nil
+}
+
+func derefStruct(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v.Elem()
+	}
+	return v
+}
+
+// isStructType reports whether an embedded field of type t is a struct
+// (or pointer to one) whose fields should be flattened into the
+// enclosing compound. Embedding a non-struct named type, e.g. a named
+// string or int, is ordinary Go and is instead marshalled like any other
+// named field, keyed by the field's name.
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// unmarshalValue stores tag into v, which must be addressable.
+func unmarshalValue(tag Tag, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(tag, v.Elem())
+	}
+
+	if v.Type() == reflect.TypeOf(Tag{}) {
+		v.Set(reflect.ValueOf(tag))
+		return nil
+	}
+	if v.Type() == reflect.TypeOf(TagList{}) {
+		if tag.Type != TAG_List {
+			return fmt.Errorf("nbt: cannot unmarshal %s into nbt.TagList", tag.Type)
+		}
+		v.Set(reflect.ValueOf(tag.Payload.(TagList)))
+		return nil
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(tag))
+		return nil
+	}
+
+	switch tag.Type {
+	case TAG_Byte:
+		return unmarshalInt(int64(tag.Payload.(byte)), v)
+	case TAG_Short:
+		return unmarshalInt(int64(tag.Payload.(int16)), v)
+	case TAG_Int:
+		return unmarshalInt(int64(tag.Payload.(int32)), v)
+	case TAG_Long:
+		return unmarshalInt(tag.Payload.(int64), v)
+	case TAG_Float:
+		return unmarshalFloat(float64(tag.Payload.(float32)), v)
+	case TAG_Double:
+		return unmarshalFloat(tag.Payload.(float64), v)
+	case TAG_String:
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("nbt: cannot unmarshal TAG_String into %s", v.Type())
+		}
+		v.SetString(tag.Payload.(string))
+		return nil
+	case TAG_Byte_Array:
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("nbt: cannot unmarshal TAG_Byte_Array into %s", v.Type())
+		}
+		data := tag.Payload.([]byte)
+		out := make([]byte, len(data))
+		copy(out, data)
+		v.Set(reflect.ValueOf(out))
+		return nil
+	case TAG_Int_Array:
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Int32 {
+			return fmt.Errorf("nbt: cannot unmarshal TAG_Int_Array into %s", v.Type())
+		}
+		data := tag.Payload.([]int32)
+		out := make([]int32, len(data))
+		copy(out, data)
+		v.Set(reflect.ValueOf(out))
+		return nil
+	case TAG_List:
+		return unmarshalList(tag.Payload.(TagList), v)
+	case TAG_Compound:
+		return unmarshalCompound(tag.Payload.(TagCompound), v)
+	}
+
+	return fmt.Errorf("nbt: cannot unmarshal %s", tag.Type)
+}
+
+func unmarshalInt(n int64, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(n))
+		return nil
+	case reflect.Bool:
+		v.SetBool(n != 0)
+		return nil
+	}
+	return fmt.Errorf("nbt: cannot unmarshal integer into %s", v.Type())
+}
+
+func unmarshalFloat(f float64, v reflect.Value) error {
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		return fmt.Errorf("nbt: cannot unmarshal float into %s", v.Type())
+	}
+	v.SetFloat(f)
+	return nil
+}
+
+func unmarshalList(list TagList, v reflect.Value) error {
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("nbt: cannot unmarshal TAG_List into %s", v.Type())
+	}
+
+	out := reflect.MakeSlice(v.Type(), len(list.Elems), len(list.Elems))
+	for i, payload := range list.Elems {
+		if err := unmarshalValue(Tag{Type: list.Type, Payload: payload}, out.Index(i)); err != nil {
+			return err
+		}
+	}
+	v.Set(out)
+	return nil
+}
+
+func unmarshalCompound(comp TagCompound, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(comp, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("nbt: unsupported map key type %s", v.Type().Key())
+		}
+		out := reflect.MakeMapWithSize(v.Type(), len(comp))
+		for name, tag := range comp {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := unmarshalValue(tag, elem); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+		}
+		v.Set(out)
+		return nil
+	}
+	return fmt.Errorf("nbt: cannot unmarshal TAG_Compound into %s", v.Type())
+}
+
+func unmarshalStruct(comp TagCompound, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		if f.Anonymous && isStructType(f.Type) {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := unmarshalStruct(comp, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ft := parseFieldTag(f)
+		if ft.skip {
+			continue
+		}
+
+		tag, ok := comp[ft.name]
+		if !ok {
+			continue
+		}
+		if err := unmarshalValue(tag, v.Field(i)); err != nil {
+			return fmt.Errorf("nbt: field %s: %w", ft.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"testing"
+)
+
+type bigTestHam struct {
+	Name  string  `nbt:"name"`
+	Value float32 `nbt:"value"`
+}
+
+type bigTestCompoundEntry struct {
+	Name      string `nbt:"name"`
+	CreatedOn int64  `nbt:"created-on"`
+}
+
+type bigTestNested struct {
+	Ham bigTestHam `nbt:"ham"`
+	Egg bigTestHam `nbt:"egg"`
+}
+
+// The real bigtest.nbt fixture stores its byte array under a key that
+// itself contains commas, which the "name,option" struct tag grammar
+// cannot represent, so that field is checked directly against the raw
+// Tag tree in TestUnmarshalBigtestByteArray instead of via this struct.
+type bigTestLevel struct {
+	LongTest   int64                  `nbt:"longTest"`
+	ShortTest  int16                  `nbt:"shortTest"`
+	StringTest string                 `nbt:"stringTest"`
+	FloatTest  float32                `nbt:"floatTest"`
+	IntTest    int32                  `nbt:"intTest"`
+	Nested     bigTestNested          `nbt:"nested compound test"`
+	LongList   []int64                `nbt:"listTest (long)"`
+	CompList   []bigTestCompoundEntry `nbt:"listTest (compound)"`
+	ByteTest   byte                   `nbt:"byteTest"`
+	DoubleTest float64                `nbt:"doubleTest"`
+}
+
+func loadBigtest(t *testing.T) []byte {
+	t.Helper()
+
+	f, err := os.Open("testdata/bigtest.nbt")
+	if err != nil {
+		t.Fatalf("opening bigtest.nbt: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("ungzipping bigtest.nbt: %v", err)
+	}
+	defer gz.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(gz); err != nil {
+		t.Fatalf("reading bigtest.nbt: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUnmarshalBigtest(t *testing.T) {
+	data := loadBigtest(t)
+
+	var level bigTestLevel
+	if _, err := NewDecoder(bytes.NewReader(data)).Decode(&level); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if level.LongTest != 9223372036854775807 {
+		t.Errorf("LongTest = %d, want 9223372036854775807", level.LongTest)
+	}
+	if level.ShortTest != 32767 {
+		t.Errorf("ShortTest = %d, want 32767", level.ShortTest)
+	}
+	if level.IntTest != 2147483647 {
+		t.Errorf("IntTest = %d, want 2147483647", level.IntTest)
+	}
+	if level.ByteTest != 127 {
+		t.Errorf("ByteTest = %d, want 127", level.ByteTest)
+	}
+	if level.Nested.Ham.Name != "Hampus" || level.Nested.Egg.Name != "Eggbert" {
+		t.Errorf("Nested = %+v, want Hampus/Eggbert", level.Nested)
+	}
+	if len(level.LongList) != 5 || level.LongList[0] != 11 || level.LongList[4] != 15 {
+		t.Errorf("LongList = %v, want [11 12 13 14 15]", level.LongList)
+	}
+	if len(level.CompList) != 2 || level.CompList[0].Name != "Compound tag #0" {
+		t.Errorf("CompList = %+v", level.CompList)
+	}
+}
+
+func TestUnmarshalBigtestByteArray(t *testing.T) {
+	data := loadBigtest(t)
+
+	tag, _, err := ReadNamedTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNamedTag: %v", err)
+	}
+
+	const key = "byteArrayTest (the first 1000 values of (n*n*255+n*7)%100, starting with n=0 (0, 62, 34, 16, 8, ...))"
+	arr, ok := tag.Payload.(TagCompound)[key].Payload.([]byte)
+	if !ok {
+		t.Fatalf("missing or wrong-typed %q", key)
+	}
+	if len(arr) != 1000 {
+		t.Fatalf("byte array has %d elements, want 1000", len(arr))
+	}
+	for n := 0; n < 1000; n++ {
+		want := byte((n*n*255 + n*7) % 100)
+		if arr[n] != want {
+			t.Fatalf("byte array[%d] = %d, want %d", n, arr[n], want)
+		}
+	}
+}
+
+func TestMarshalUnmarshalRoundtrip(t *testing.T) {
+	data := loadBigtest(t)
+
+	var level bigTestLevel
+	if _, err := NewDecoder(bytes.NewReader(data)).Decode(&level); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode("Level", &level); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var roundtripped bigTestLevel
+	if _, err := NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&roundtripped); err != nil {
+		t.Fatalf("Decode roundtrip: %v", err)
+	}
+
+	if roundtripped.StringTest != level.StringTest {
+		t.Errorf("StringTest roundtrip = %q, want %q", roundtripped.StringTest, level.StringTest)
+	}
+	if roundtripped.DoubleTest != level.DoubleTest {
+		t.Errorf("DoubleTest roundtrip = %v, want %v", roundtripped.DoubleTest, level.DoubleTest)
+	}
+}
+
+type omitStruct struct {
+	Kept   int32  `nbt:"kept"`
+	Omit   string `nbt:"omit,omitempty"`
+	Hidden int32  `nbt:"-"`
+}
+
+func TestMarshalOmitempty(t *testing.T) {
+	v := omitStruct{Kept: 1, Hidden: 2}
+
+	data, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	tag, _, err := ReadNamedTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNamedTag: %v", err)
+	}
+
+	comp := tag.Payload.(TagCompound)
+	if _, ok := comp["omit"]; ok {
+		t.Error("empty omitempty field was not omitted")
+	}
+	if _, ok := comp["Hidden"]; ok {
+		t.Error("skipped field was marshaled")
+	}
+	if comp["kept"].Payload.(int32) != 1 {
+		t.Errorf("kept = %v, want 1", comp["kept"].Payload)
+	}
+}
+
+type EntityID string
+
+type embeddedNonStruct struct {
+	EntityID
+	Name string `nbt:"Name"`
+}
+
+func TestMarshalUnmarshalEmbeddedNonStruct(t *testing.T) {
+	v := embeddedNonStruct{EntityID: "creeper", Name: "Steve"}
+
+	data, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	tag, _, err := ReadNamedTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNamedTag: %v", err)
+	}
+	comp := tag.Payload.(TagCompound)
+	if comp["EntityID"].Payload.(string) != "creeper" {
+		t.Errorf("EntityID = %+v, want creeper", comp["EntityID"])
+	}
+	if comp["Name"].Payload.(string) != "Steve" {
+		t.Errorf("Name = %+v, want Steve", comp["Name"])
+	}
+
+	var got embeddedNonStruct
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != v {
+		t.Errorf("Unmarshal roundtrip = %+v, want %+v", got, v)
+	}
+}
+
+type entityWithRawList struct {
+	Items TagList `nbt:"Items"`
+}
+
+func TestMarshalUnmarshalTagListField(t *testing.T) {
+	v := entityWithRawList{Items: TagList{Type: TAG_String, Elems: []interface{}{"a", "b"}}}
+
+	data, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	tag, _, err := ReadNamedTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadNamedTag: %v", err)
+	}
+	comp := tag.Payload.(TagCompound)
+	items, ok := comp["Items"]
+	if !ok || items.Type != TAG_List {
+		t.Fatalf("Items = %+v, want a TAG_List", items)
+	}
+	list := items.Payload.(TagList)
+	if list.Type != TAG_String || len(list.Elems) != 2 || list.Elems[0].(string) != "a" {
+		t.Errorf("Items list = %+v", list)
+	}
+
+	var got entityWithRawList
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Items.Type != TAG_String || len(got.Items.Elems) != 2 || got.Items.Elems[1].(string) != "b" {
+		t.Errorf("Unmarshal roundtrip Items = %+v", got.Items)
+	}
+}
@@ -1,7 +1,6 @@
 package nbt
 
 import (
-	"encoding/binary"
 	"errors"
 	"io"
 )
@@ -68,79 +67,58 @@ type TagCompound map[string]Tag
 
 func readByte(r io.Reader) (byte, error) {
 	buf := make([]byte, 1)
-	_, err := r.Read(buf)
+	_, err := io.ReadFull(r, buf)
 	return buf[0], err
 }
 
-func readTagData(r io.Reader, tt TagType) (interface{}, error) {
+func readTagData(r io.Reader, tt TagType, flavor Flavor) (interface{}, error) {
 	switch tt {
 	case TAG_End:
 	case TAG_Byte:
-		var v uint8
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return readByte(r)
 	case TAG_Short:
-		var v int16
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return flavor.ReadInt16(r)
 	case TAG_Int:
-		var v int32
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return flavor.ReadInt32(r)
 	case TAG_Long:
-		var v int64
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return flavor.ReadInt64(r)
 	case TAG_Float:
-		var v float32
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return flavor.ReadFloat32(r)
 	case TAG_Double:
-		var v float64
-		err := binary.Read(r, binary.BigEndian, &v)
-		return v, err
+		return flavor.ReadFloat64(r)
 	case TAG_Byte_Array:
-		var l int32
-		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		l, err := flavor.ReadArrayLen(r)
+		if err != nil {
 			return nil, err
 		}
-		if l < 0 {
-			return nil, errors.New("Byte array has negative length?")
-		}
 
 		data := make([]byte, l)
-		_, err := io.ReadFull(r, data)
+		_, err = io.ReadFull(r, data)
 		return data, err
 	case TAG_String:
-		var l int16
-		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		l, err := flavor.ReadStringLen(r)
+		if err != nil {
 			return nil, err
 		}
-		if l < 0 {
-			return nil, errors.New("String has negative length?")
-		}
 
 		data := make([]byte, l)
-		_, err := io.ReadFull(r, data)
+		_, err = io.ReadFull(r, data)
 		return string(data), err
 	case TAG_List:
-		ltt, err := readByte(r)
+		_ltt, err := readByte(r)
 		if err != nil {
 			return nil, err
 		}
 		ltt := TagType(_ltt)
 
-		var l int32
-		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		l, err := flavor.ReadArrayLen(r)
+		if err != nil {
 			return nil, err
 		}
-		if l < 0 {
-			return nil, errors.New("List has negative length?")
-		}
 
 		tl := TagList{Type: ltt, Elems: make([]interface{}, l)}
-		for i := 0; i < int(l); i++ {
-			if tl.Elems[i], err = readTagData(r, ltt); err != nil {
+		for i := 0; i < l; i++ {
+			if tl.Elems[i], err = readTagData(r, ltt, flavor); err != nil {
 				return nil, err
 			}
 		}
@@ -148,7 +126,7 @@ func readTagData(r io.Reader, tt TagType) (interface{}, error) {
 	case TAG_Compound:
 		comp := make(TagCompound)
 		for {
-			tag, name, err := ReadNamedTag(r)
+			tag, name, err := readNamedTag(r, flavor)
 			if err != nil {
 				return nil, err
 			}
@@ -159,21 +137,16 @@ func readTagData(r io.Reader, tt TagType) (interface{}, error) {
 		}
 		return comp, nil
 	case TAG_Int_Array:
-		var l int32
-		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		l, err := flavor.ReadArrayLen(r)
+		if err != nil {
 			return nil, err
 		}
-		if l < 0 {
-			return nil, errors.New("Int Array has negative length?")
-		}
 
 		data := make([]int32, l)
-		for i := 0; i < int(l); i++ {
-			var e int32
-			if err := binary.Read(r, binary.BigEndian, &e); err != nil {
+		for i := range data {
+			if data[i], err = flavor.ReadInt32(r); err != nil {
 				return nil, err
 			}
-			data[i] = e
 		}
 		return data, nil
 	}
@@ -181,8 +154,8 @@ func readTagData(r io.Reader, tt TagType) (interface{}, error) {
 	return nil, errors.New("Unknown tag type")
 }
 
-func ReadNamedTag(r io.Reader) (Tag, string, error) {
-	_tt, err := kagus.ReadByte(r)
+func readNamedTag(r io.Reader, flavor Flavor) (Tag, string, error) {
+	_tt, err := readByte(r)
 	if err != nil {
 		return Tag{}, "", err
 	}
@@ -192,46 +165,58 @@ func ReadNamedTag(r io.Reader) (Tag, string, error) {
 		return Tag{Type: tt}, "", nil
 	}
 
-	name, err := readTagData(r, TAG_String)
+	name, err := readTagData(r, TAG_String, flavor)
 	if err != nil {
 		return Tag{}, "", err
 	}
 
-	td, err := readTagData(r, tt)
+	td, err := readTagData(r, tt, flavor)
 	return Tag{Type: tt, Payload: td}, name.(string), err
 }
 
+// ReadNamedTag reads a named tag encoded as Java Edition's big-endian
+// NBT. Use ReadNamedTagFlavor to read other dialects, such as Bedrock
+// Edition's little-endian or network encodings.
+func ReadNamedTag(r io.Reader) (Tag, string, error) {
+	return readNamedTag(r, JavaBigEndian)
+}
+
+// ReadNamedTagFlavor reads a named tag encoded according to flavor.
+func ReadNamedTagFlavor(r io.Reader, flavor Flavor) (Tag, string, error) {
+	return readNamedTag(r, flavor)
+}
+
 func writeByte(w io.Writer, b byte) error {
 	_, err := w.Write([]byte{b})
 	return err
 }
 
-func writeTagData(w io.Writer, tt TagType, data interface{}) error {
+func writeTagData(w io.Writer, tt TagType, data interface{}, flavor Flavor) error {
 	switch tt {
 	case TAG_End:
 		return nil
 	case TAG_Byte:
 		return writeByte(w, data.(byte))
 	case TAG_Short:
-		return binary.Write(w, binary.BigEndian, data.(int16))
+		return flavor.WriteInt16(w, data.(int16))
 	case TAG_Int:
-		return binary.Write(w, binary.BigEndian, data.(int32))
+		return flavor.WriteInt32(w, data.(int32))
 	case TAG_Long:
-		return binary.Write(w, binary.BigEndian, data.(int64))
+		return flavor.WriteInt64(w, data.(int64))
 	case TAG_Float:
-		return binary.Write(w, binary.BigEndian, data.(float32))
+		return flavor.WriteFloat32(w, data.(float32))
 	case TAG_Double:
-		return binary.Write(w, binary.BigEndian, data.(float64))
+		return flavor.WriteFloat64(w, data.(float64))
 	case TAG_Byte_Array:
 		slice := data.([]byte)
-		if err := binary.Write(w, binary.BigEndian, int32(len(slice))); err != nil {
+		if err := flavor.WriteArrayLen(w, len(slice)); err != nil {
 			return err
 		}
 		_, err := w.Write(slice)
 		return err
 	case TAG_String:
 		strEnc := []byte(data.(string))
-		if err := binary.Write(w, binary.BigEndian, int16(len(strEnc))); err != nil {
+		if err := flavor.WriteStringLen(w, len(strEnc)); err != nil {
 			return err
 		}
 		_, err := w.Write(strEnc)
@@ -242,12 +227,12 @@ func writeTagData(w io.Writer, tt TagType, data interface{}) error {
 			return err
 		}
 
-		if err := binary.Write(w, binary.BigEndian, int32(len(list.Elems))); err != nil {
+		if err := flavor.WriteArrayLen(w, len(list.Elems)); err != nil {
 			return err
 		}
 
 		for _, el := range list.Elems {
-			if err := writeTagData(w, list.Type, el); err != nil {
+			if err := writeTagData(w, list.Type, el, flavor); err != nil {
 				return err
 			}
 		}
@@ -255,19 +240,19 @@ func writeTagData(w io.Writer, tt TagType, data interface{}) error {
 	case TAG_Compound:
 		comp := data.(TagCompound)
 		for name, tag := range comp {
-			if err := WriteNamedTag(w, name, tag); err != nil {
+			if err := writeNamedTag(w, name, tag, flavor); err != nil {
 				return err
 			}
 		}
 		return writeByte(w, TAG_End)
 	case TAG_Int_Array:
 		slice := data.([]int32)
-		if err := binary.Write(w, binary.BigEndian, int32(len(slice))); err != nil {
+		if err := flavor.WriteArrayLen(w, len(slice)); err != nil {
 			return err
 		}
 
 		for _, el := range slice {
-			if err := binary.Write(w, binary.BigEndian, el); err != nil {
+			if err := flavor.WriteInt32(w, el); err != nil {
 				return err
 			}
 		}
@@ -278,14 +263,26 @@ func writeTagData(w io.Writer, tt TagType, data interface{}) error {
 	return errors.New("Unknown tage type")
 }
 
-func WriteNamedTag(w io.Writer, name string, tag Tag) error {
+func writeNamedTag(w io.Writer, name string, tag Tag, flavor Flavor) error {
 	if err := writeByte(w, byte(tag.Type)); err != nil {
 		return err
 	}
 
-	if err := writeTagData(w, TAG_String, name); err != nil {
+	if err := writeTagData(w, TAG_String, name, flavor); err != nil {
 		return err
 	}
 
-	return writeTagData(w, tag.Type, tag.Payload)
-}
\ No newline at end of file
+	return writeTagData(w, tag.Type, tag.Payload, flavor)
+}
+
+// WriteNamedTag writes a named tag encoded as Java Edition's big-endian
+// NBT. Use WriteNamedTagFlavor to write other dialects, such as Bedrock
+// Edition's little-endian or network encodings.
+func WriteNamedTag(w io.Writer, name string, tag Tag) error {
+	return writeNamedTag(w, name, tag, JavaBigEndian)
+}
+
+// WriteNamedTagFlavor writes a named tag encoded according to flavor.
+func WriteNamedTagFlavor(w io.Writer, name string, tag Tag, flavor Flavor) error {
+	return writeNamedTag(w, name, tag, flavor)
+}
@@ -0,0 +1,461 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathSegKind int
+
+const (
+	pathSegField pathSegKind = iota
+	pathSegIndex
+	pathSegWildcard
+)
+
+type pathSeg struct {
+	kind  pathSegKind
+	field string
+	index int
+}
+
+func (s pathSeg) String() string {
+	switch s.kind {
+	case pathSegField:
+		return "." + s.field
+	case pathSegIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	default:
+		return "[*]"
+	}
+}
+
+// Path is a parsed reference into a Tag tree, such as
+// Level.Sections[3].Palette[0].Name, usable to read or mutate deeply
+// nested Minecraft structures without hand-written type assertions.
+type Path struct {
+	raw  string
+	segs []pathSeg
+}
+
+// ParsePath parses s using the grammar `.field`, `["quoted field"]`,
+// `[index]` and `[*]` (a wildcard matching every element of a list).
+// The very first segment is written without a leading dot, e.g.
+// `Level.Sections[3].Palette[0].Name`.
+func ParsePath(s string) (Path, error) {
+	p := &pathParser{src: s}
+	segs, err := p.parse()
+	if err != nil {
+		return Path{}, fmt.Errorf("nbt: invalid path %q: %w", s, err)
+	}
+	return Path{raw: s, segs: segs}, nil
+}
+
+// String returns the path's original, as-parsed text.
+func (p Path) String() string {
+	return p.raw
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) parse() ([]pathSeg, error) {
+	var segs []pathSeg
+
+	if p.pos < len(p.src) && p.src[p.pos] != '.' && p.src[p.pos] != '[' {
+		field, err := p.parseBareField()
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, pathSeg{kind: pathSegField, field: field})
+	}
+
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '.':
+			p.pos++
+			field, err := p.parseBareField()
+			if err != nil {
+				return nil, err
+			}
+			segs = append(segs, pathSeg{kind: pathSegField, field: field})
+		case '[':
+			seg, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			segs = append(segs, seg)
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos], p.pos)
+		}
+	}
+
+	if len(segs) == 0 {
+		return nil, errors.New("empty path")
+	}
+
+	return segs, nil
+}
+
+func (p *pathParser) parseBareField() (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '.' && p.src[p.pos] != '[' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected a field name at offset %d", start)
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *pathParser) parseBracket() (pathSeg, error) {
+	start := p.pos
+	p.pos++ // consume '['
+
+	if p.pos >= len(p.src) {
+		return pathSeg{}, fmt.Errorf("unterminated '[' at offset %d", start)
+	}
+
+	if p.src[p.pos] == '*' {
+		p.pos++
+		if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return pathSeg{}, fmt.Errorf("expected ']' after '[*' at offset %d", start)
+		}
+		p.pos++
+		return pathSeg{kind: pathSegWildcard}, nil
+	}
+
+	if p.src[p.pos] == '"' {
+		field, err := p.parseQuoted()
+		if err != nil {
+			return pathSeg{}, err
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return pathSeg{}, fmt.Errorf("expected ']' after quoted field at offset %d", start)
+		}
+		p.pos++
+		return pathSeg{kind: pathSegField, field: field}, nil
+	}
+
+	digitsStart := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == digitsStart || p.pos >= len(p.src) || p.src[p.pos] != ']' {
+		return pathSeg{}, fmt.Errorf("expected an index or ']' at offset %d", start)
+	}
+	index, err := strconv.Atoi(p.src[digitsStart:p.pos])
+	if err != nil {
+		return pathSeg{}, fmt.Errorf("invalid index at offset %d: %w", digitsStart, err)
+	}
+	p.pos++
+	return pathSeg{kind: pathSegIndex, index: index}, nil
+}
+
+func (p *pathParser) parseQuoted() (string, error) {
+	p.pos++ // consume opening quote
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", errors.New("unterminated quoted field name")
+		}
+		c := p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			return sb.String(), nil
+		}
+		if c == '\\' && p.pos+1 < len(p.src) {
+			p.pos++
+			sb.WriteByte(p.src[p.pos])
+			p.pos++
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+// Get resolves path against root, returning every matching Tag. A
+// wildcard segment expands into one result per list element; every
+// other segment produces at most one.
+func (p Path) Get(root Tag) ([]Tag, error) {
+	tags, err := getAtPath(root, p.segs)
+	if err != nil {
+		return nil, fmt.Errorf("nbt: path %q: %w", p.raw, err)
+	}
+	return tags, nil
+}
+
+func getAtPath(cur Tag, segs []pathSeg) ([]Tag, error) {
+	if len(segs) == 0 {
+		return []Tag{cur}, nil
+	}
+
+	seg, rest := segs[0], segs[1:]
+
+	switch seg.kind {
+	case pathSegField:
+		comp, ok := cur.Payload.(TagCompound)
+		if !ok {
+			return nil, fmt.Errorf("segment %s: not a TAG_Compound (got %s)", seg, cur.Type)
+		}
+		child, ok := comp[seg.field]
+		if !ok {
+			return nil, fmt.Errorf("segment %s: field not found", seg)
+		}
+		return getAtPath(child, rest)
+	case pathSegIndex:
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return nil, fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		if seg.index < 0 || seg.index >= len(list.Elems) {
+			return nil, fmt.Errorf("segment %s: index out of range (len %d)", seg, len(list.Elems))
+		}
+		return getAtPath(Tag{Type: list.Type, Payload: list.Elems[seg.index]}, rest)
+	default: // pathSegWildcard
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return nil, fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		var results []Tag
+		for _, el := range list.Elems {
+			sub, err := getAtPath(Tag{Type: list.Type, Payload: el}, rest)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, sub...)
+		}
+		return results, nil
+	}
+}
+
+// Set resolves all but the last segment of path against root, then
+// stores v under the final segment, creating the field if it does not
+// already exist. A wildcard in the final segment sets every element of
+// the matched list.
+func (p Path) Set(root *Tag, v Tag) error {
+	if len(p.segs) == 0 {
+		return fmt.Errorf("nbt: path %q: empty path", p.raw)
+	}
+	if err := setAtPath(root, p.segs, v); err != nil {
+		return fmt.Errorf("nbt: path %q: %w", p.raw, err)
+	}
+	return nil
+}
+
+func setAtPath(cur *Tag, segs []pathSeg, v Tag) error {
+	if len(segs) == 0 {
+		*cur = v
+		return nil
+	}
+
+	seg, rest := segs[0], segs[1:]
+
+	switch seg.kind {
+	case pathSegField:
+		comp, ok := cur.Payload.(TagCompound)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_Compound (got %s)", seg, cur.Type)
+		}
+		child := comp[seg.field]
+		if err := setAtPath(&child, rest, v); err != nil {
+			return err
+		}
+		comp[seg.field] = child
+		return nil
+	case pathSegIndex:
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		if seg.index < 0 || seg.index >= len(list.Elems) {
+			return fmt.Errorf("segment %s: index out of range (len %d)", seg, len(list.Elems))
+		}
+		child := Tag{Type: list.Type, Payload: list.Elems[seg.index]}
+		if err := setAtPath(&child, rest, v); err != nil {
+			return err
+		}
+		if child.Type != list.Type {
+			if len(list.Elems) != 1 {
+				return fmt.Errorf("segment %s: cannot set a %s element in a %s list", seg, child.Type, list.Type)
+			}
+			list.Type = child.Type
+		}
+		list.Elems[seg.index] = child.Payload
+		cur.Payload = list
+		return nil
+	default: // pathSegWildcard
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		elemType := list.Type
+		for i, el := range list.Elems {
+			child := Tag{Type: elemType, Payload: el}
+			if err := setAtPath(&child, rest, v); err != nil {
+				return err
+			}
+			// Every element is set via the same rest/v, so once the
+			// whole loop is done their types agree even if that
+			// differs from the list's original element type.
+			list.Type = child.Type
+			list.Elems[i] = child.Payload
+		}
+		cur.Payload = list
+		return nil
+	}
+}
+
+// Delete resolves all but the last segment of path against root, then
+// removes the final segment: a compound key, a single list element, or
+// (via a final wildcard) every element of the matched list.
+func (p Path) Delete(root *Tag) error {
+	if len(p.segs) == 0 {
+		return fmt.Errorf("nbt: path %q: empty path", p.raw)
+	}
+	if err := deleteAtPath(root, p.segs); err != nil {
+		return fmt.Errorf("nbt: path %q: %w", p.raw, err)
+	}
+	return nil
+}
+
+func deleteAtPath(cur *Tag, segs []pathSeg) error {
+	seg, rest := segs[0], segs[1:]
+
+	if len(rest) == 0 {
+		switch seg.kind {
+		case pathSegField:
+			comp, ok := cur.Payload.(TagCompound)
+			if !ok {
+				return fmt.Errorf("segment %s: not a TAG_Compound (got %s)", seg, cur.Type)
+			}
+			if _, ok := comp[seg.field]; !ok {
+				return fmt.Errorf("segment %s: field not found", seg)
+			}
+			delete(comp, seg.field)
+			return nil
+		case pathSegIndex:
+			list, ok := cur.Payload.(TagList)
+			if !ok {
+				return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+			}
+			if seg.index < 0 || seg.index >= len(list.Elems) {
+				return fmt.Errorf("segment %s: index out of range (len %d)", seg, len(list.Elems))
+			}
+			list.Elems = append(list.Elems[:seg.index], list.Elems[seg.index+1:]...)
+			cur.Payload = list
+			return nil
+		default: // pathSegWildcard
+			list, ok := cur.Payload.(TagList)
+			if !ok {
+				return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+			}
+			list.Elems = nil
+			cur.Payload = list
+			return nil
+		}
+	}
+
+	switch seg.kind {
+	case pathSegField:
+		comp, ok := cur.Payload.(TagCompound)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_Compound (got %s)", seg, cur.Type)
+		}
+		child, ok := comp[seg.field]
+		if !ok {
+			return fmt.Errorf("segment %s: field not found", seg)
+		}
+		if err := deleteAtPath(&child, rest); err != nil {
+			return err
+		}
+		comp[seg.field] = child
+		return nil
+	case pathSegIndex:
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		if seg.index < 0 || seg.index >= len(list.Elems) {
+			return fmt.Errorf("segment %s: index out of range (len %d)", seg, len(list.Elems))
+		}
+		child := Tag{Type: list.Type, Payload: list.Elems[seg.index]}
+		if err := deleteAtPath(&child, rest); err != nil {
+			return err
+		}
+		list.Elems[seg.index] = child.Payload
+		cur.Payload = list
+		return nil
+	default: // pathSegWildcard
+		list, ok := cur.Payload.(TagList)
+		if !ok {
+			return fmt.Errorf("segment %s: not a TAG_List (got %s)", seg, cur.Type)
+		}
+		for i, el := range list.Elems {
+			child := Tag{Type: list.Type, Payload: el}
+			if err := deleteAtPath(&child, rest); err != nil {
+				return err
+			}
+			list.Elems[i] = child.Payload
+		}
+		cur.Payload = list
+		return nil
+	}
+}
+
+// GetInt resolves path against root and returns its TAG_Int payload.
+// It returns (0, false) if the path does not resolve to exactly one
+// TAG_Int.
+func (p Path) GetInt(root Tag) (int32, bool) {
+	v, ok := p.getOne(root)
+	if !ok || v.Type != TAG_Int {
+		return 0, false
+	}
+	return v.Payload.(int32), true
+}
+
+// GetString resolves path against root and returns its TAG_String
+// payload. It returns ("", false) if the path does not resolve to
+// exactly one TAG_String.
+func (p Path) GetString(root Tag) (string, bool) {
+	v, ok := p.getOne(root)
+	if !ok || v.Type != TAG_String {
+		return "", false
+	}
+	return v.Payload.(string), true
+}
+
+// GetCompound resolves path against root and returns its TAG_Compound
+// payload. It returns (nil, false) if the path does not resolve to
+// exactly one TAG_Compound.
+func (p Path) GetCompound(root Tag) (TagCompound, bool) {
+	v, ok := p.getOne(root)
+	if !ok || v.Type != TAG_Compound {
+		return nil, false
+	}
+	return v.Payload.(TagCompound), true
+}
+
+// GetList resolves path against root and returns its TAG_List payload.
+// It returns (TagList{}, false) if the path does not resolve to exactly
+// one TAG_List.
+func (p Path) GetList(root Tag) (TagList, bool) {
+	v, ok := p.getOne(root)
+	if !ok || v.Type != TAG_List {
+		return TagList{}, false
+	}
+	return v.Payload.(TagList), true
+}
+
+func (p Path) getOne(root Tag) (Tag, bool) {
+	tags, err := p.Get(root)
+	if err != nil || len(tags) != 1 {
+		return Tag{}, false
+	}
+	return tags[0], true
+}
@@ -0,0 +1,226 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func buildPathTestTag() Tag {
+	return Tag{Type: TAG_Compound, Payload: TagCompound{
+		"Level": Tag{Type: TAG_Compound, Payload: TagCompound{
+			"Sections": Tag{Type: TAG_List, Payload: TagList{Type: TAG_Compound, Elems: []interface{}{
+				TagCompound{"Y": Tag{Type: TAG_Byte, Payload: byte(0)}},
+				TagCompound{
+					"Y": Tag{Type: TAG_Byte, Payload: byte(1)},
+					"Palette": Tag{Type: TAG_List, Payload: TagList{Type: TAG_Compound, Elems: []interface{}{
+						TagCompound{"Name": Tag{Type: TAG_String, Payload: "minecraft:air"}},
+						TagCompound{"Name": Tag{Type: TAG_String, Payload: "minecraft:stone"}},
+					}}},
+				},
+			}}},
+		}},
+	}}
+}
+
+func TestPathGet(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.Sections[1].Palette[0].Name`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+
+	name, ok := path.GetString(root)
+	if !ok || name != "minecraft:air" {
+		t.Fatalf("GetString = (%q, %v), want (minecraft:air, true)", name, ok)
+	}
+}
+
+func TestPathGetWildcard(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.Sections[1].Palette[*].Name`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+
+	tags, err := path.Get(root)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Payload.(string) != "minecraft:air" || tags[1].Payload.(string) != "minecraft:stone" {
+		t.Fatalf("Get(wildcard) = %+v", tags)
+	}
+}
+
+func TestPathSet(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.Sections[1].Palette[0].Name`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+
+	if err := path.Set(&root, Tag{Type: TAG_String, Payload: "minecraft:dirt"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, ok := path.GetString(root)
+	if !ok || got != "minecraft:dirt" {
+		t.Fatalf("GetString after Set = (%q, %v)", got, ok)
+	}
+}
+
+func TestPathSetCreatesField(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.NewField`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	if err := path.Set(&root, Tag{Type: TAG_Int, Payload: int32(42)}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	n, ok := path.GetInt(root)
+	if !ok || n != 42 {
+		t.Fatalf("GetInt = (%d, %v)", n, ok)
+	}
+}
+
+func TestPathDelete(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.Sections[1].Palette[0]`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	if err := path.Delete(&root); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	listPath, err := ParsePath(`Level.Sections[1].Palette`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	list, ok := listPath.GetList(root)
+	if !ok || len(list.Elems) != 1 {
+		t.Fatalf("Palette after delete = %+v", list)
+	}
+	if list.Elems[0].(TagCompound)["Name"].Payload.(string) != "minecraft:stone" {
+		t.Fatalf("remaining element = %+v", list.Elems[0])
+	}
+}
+
+func TestPathGetMissingField(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.DoesNotExist`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	if _, err := path.Get(root); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
+
+func TestPathSetRejectsListElementTypeMismatch(t *testing.T) {
+	root := buildPathTestTag()
+
+	path, err := ParsePath(`Level.Sections[1].Palette[0]`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+
+	if err := path.Set(&root, Tag{Type: TAG_Int, Payload: int32(1)}); err == nil {
+		t.Fatal("expected an error setting a list element to a different tag type")
+	}
+
+	listPath, err := ParsePath(`Level.Sections[1].Palette`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	list, ok := listPath.GetList(root)
+	if !ok || list.Type != TAG_Compound {
+		t.Fatalf("Palette.Type after rejected Set = %v, want unchanged TAG_Compound", list.Type)
+	}
+}
+
+func TestPathSetSingletonListAdoptsNewElementType(t *testing.T) {
+	root := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"Values": Tag{Type: TAG_List, Payload: TagList{Type: TAG_Int, Elems: []interface{}{int32(1)}}},
+	}}
+
+	path, err := ParsePath(`Values[0]`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	if err := path.Set(&root, Tag{Type: TAG_String, Payload: "one"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNamedTag(&buf, "", root); err != nil {
+		t.Fatalf("WriteNamedTag: %v", err)
+	}
+
+	listPath, err := ParsePath(`Values`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	list, ok := listPath.GetList(root)
+	if !ok || list.Type != TAG_String || list.Elems[0].(string) != "one" {
+		t.Fatalf("Values after Set = %+v", list)
+	}
+}
+
+func TestPathSetWildcardAdoptsNewElementType(t *testing.T) {
+	root := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"Values": Tag{Type: TAG_List, Payload: TagList{Type: TAG_Int, Elems: []interface{}{int32(1), int32(2)}}},
+	}}
+
+	path, err := ParsePath(`Values[*]`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	if err := path.Set(&root, Tag{Type: TAG_String, Payload: "x"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNamedTag(&buf, "", root); err != nil {
+		t.Fatalf("WriteNamedTag: %v", err)
+	}
+
+	listPath, err := ParsePath(`Values`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+	list, ok := listPath.GetList(root)
+	if !ok || list.Type != TAG_String {
+		t.Fatalf("Values.Type after wildcard Set = %v, want TAG_String", list.Type)
+	}
+	for i, el := range list.Elems {
+		if el.(string) != "x" {
+			t.Errorf("Values[%d] = %v, want x", i, el)
+		}
+	}
+}
+
+func TestParsePathQuotedField(t *testing.T) {
+	path, err := ParsePath(`["a.b"].c`)
+	if err != nil {
+		t.Fatalf("ParsePath: %v", err)
+	}
+
+	root := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"a.b": Tag{Type: TAG_Compound, Payload: TagCompound{
+			"c": Tag{Type: TAG_Int, Payload: int32(7)},
+		}},
+	}}
+
+	n, ok := path.GetInt(root)
+	if !ok || n != 7 {
+		t.Fatalf("GetInt = (%d, %v)", n, ok)
+	}
+}
@@ -0,0 +1,483 @@
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MarshalSNBT renders tag using Mojang's stringified NBT (SNBT) grammar,
+// the textual form accepted by commands such as /data merge and produced
+// by /data get.
+func MarshalSNBT(tag Tag) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeSNBT(&buf, tag); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSNBT(buf *bytes.Buffer, tag Tag) error {
+	switch tag.Type {
+	case TAG_Byte:
+		fmt.Fprintf(buf, "%db", int8(tag.Payload.(byte)))
+	case TAG_Short:
+		fmt.Fprintf(buf, "%ds", tag.Payload.(int16))
+	case TAG_Int:
+		fmt.Fprintf(buf, "%d", tag.Payload.(int32))
+	case TAG_Long:
+		fmt.Fprintf(buf, "%dL", tag.Payload.(int64))
+	case TAG_Float:
+		fmt.Fprintf(buf, "%sf", strconv.FormatFloat(float64(tag.Payload.(float32)), 'g', -1, 32))
+	case TAG_Double:
+		fmt.Fprintf(buf, "%sd", strconv.FormatFloat(tag.Payload.(float64), 'g', -1, 64))
+	case TAG_String:
+		writeSNBTString(buf, tag.Payload.(string))
+	case TAG_Byte_Array:
+		data := tag.Payload.([]byte)
+		buf.WriteString("[B;")
+		for i, b := range data {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%db", int8(b))
+		}
+		buf.WriteByte(']')
+	case TAG_Int_Array:
+		data := tag.Payload.([]int32)
+		buf.WriteString("[I;")
+		for i, v := range data {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%d", v)
+		}
+		buf.WriteByte(']')
+	case TAG_List:
+		list := tag.Payload.(TagList)
+		if list.Type == TAG_Long {
+			buf.WriteString("[L;")
+			for i, el := range list.Elems {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				fmt.Fprintf(buf, "%dL", el.(int64))
+			}
+			buf.WriteByte(']')
+			return nil
+		}
+
+		buf.WriteByte('[')
+		for i, el := range list.Elems {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeSNBT(buf, Tag{Type: list.Type, Payload: el}); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case TAG_Compound:
+		comp := tag.Payload.(TagCompound)
+		buf.WriteByte('{')
+		first := true
+		for name, t := range comp {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeSNBTKey(buf, name)
+			buf.WriteByte(':')
+			if err := writeSNBT(buf, t); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("nbt: cannot render %s as SNBT", tag.Type)
+	}
+
+	return nil
+}
+
+func isBareWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '+' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func writeSNBTKey(buf *bytes.Buffer, s string) {
+	if isBareWord(s) {
+		buf.WriteString(s)
+		return
+	}
+	writeSNBTString(buf, s)
+}
+
+func writeSNBTString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// UnmarshalSNBT parses data as stringified NBT and returns the resulting
+// tag tree.
+func UnmarshalSNBT(data []byte) (Tag, error) {
+	p := &snbtParser{src: string(data)}
+	p.skipSpace()
+	tag, err := p.parseValue()
+	if err != nil {
+		return Tag{}, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return Tag{}, p.errorf("unexpected trailing data")
+	}
+	return tag, nil
+}
+
+type snbtParser struct {
+	src string
+	pos int
+}
+
+func (p *snbtParser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("nbt: SNBT parse error at offset %d: %s", p.pos, msg)
+}
+
+func (p *snbtParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *snbtParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *snbtParser) skipSpace() {
+	for !p.eof() {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *snbtParser) parseValue() (Tag, error) {
+	if p.eof() {
+		return Tag{}, p.errorf("unexpected end of input")
+	}
+
+	switch p.peek() {
+	case '{':
+		return p.parseCompound()
+	case '[':
+		return p.parseArrayOrList()
+	case '"':
+		s, err := p.parseQuotedString()
+		if err != nil {
+			return Tag{}, err
+		}
+		return Tag{Type: TAG_String, Payload: s}, nil
+	}
+
+	return p.parseBareValue()
+}
+
+func (p *snbtParser) parseCompound() (Tag, error) {
+	p.pos++ // consume '{'
+	comp := make(TagCompound)
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return Tag{Type: TAG_Compound, Payload: comp}, nil
+	}
+
+	for {
+		p.skipSpace()
+		key, err := p.parseKey()
+		if err != nil {
+			return Tag{}, err
+		}
+
+		p.skipSpace()
+		if p.peek() != ':' {
+			return Tag{}, p.errorf("expected ':' after compound key %q", key)
+		}
+		p.pos++
+
+		p.skipSpace()
+		val, err := p.parseValue()
+		if err != nil {
+			return Tag{}, err
+		}
+		comp[key] = val
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case '}':
+			p.pos++
+			return Tag{Type: TAG_Compound, Payload: comp}, nil
+		default:
+			return Tag{}, p.errorf("expected ',' or '}' in compound")
+		}
+	}
+}
+
+func (p *snbtParser) parseKey() (string, error) {
+	if p.peek() == '"' {
+		return p.parseQuotedString()
+	}
+
+	start := p.pos
+	for !p.eof() && isKeyByte(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected compound key")
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isKeyByte(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b == '.' || b == '+' || b == '-'
+}
+
+func (p *snbtParser) parseArrayOrList() (Tag, error) {
+	p.pos++ // consume '['
+
+	if p.pos+1 < len(p.src) && p.src[p.pos+1] == ';' {
+		switch p.src[p.pos] {
+		case 'B':
+			return p.parseTypedArray('B')
+		case 'I':
+			return p.parseTypedArray('I')
+		case 'L':
+			return p.parseTypedArray('L')
+		}
+	}
+
+	return p.parseList()
+}
+
+func (p *snbtParser) parseTypedArray(kind byte) (Tag, error) {
+	p.pos += 2 // consume e.g. "B;"
+
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		switch kind {
+		case 'B':
+			return Tag{Type: TAG_Byte_Array, Payload: []byte{}}, nil
+		case 'I':
+			return Tag{Type: TAG_Int_Array, Payload: []int32{}}, nil
+		default:
+			return Tag{Type: TAG_List, Payload: TagList{Type: TAG_Long, Elems: []interface{}{}}}, nil
+		}
+	}
+
+	var bytesOut []byte
+	var intsOut []int32
+	var longsOut []interface{}
+
+	for {
+		p.skipSpace()
+		tag, err := p.parseBareValue()
+		if err != nil {
+			return Tag{}, err
+		}
+
+		switch kind {
+		case 'B':
+			if tag.Type != TAG_Byte {
+				return Tag{}, p.errorf("expected byte in byte array")
+			}
+			bytesOut = append(bytesOut, tag.Payload.(byte))
+		case 'I':
+			if tag.Type != TAG_Int {
+				return Tag{}, p.errorf("expected int in int array")
+			}
+			intsOut = append(intsOut, tag.Payload.(int32))
+		case 'L':
+			var v int64
+			switch tag.Type {
+			case TAG_Long:
+				v = tag.Payload.(int64)
+			case TAG_Int:
+				v = int64(tag.Payload.(int32))
+			default:
+				return Tag{}, p.errorf("expected long in long array")
+			}
+			longsOut = append(longsOut, v)
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case ']':
+			p.pos++
+			switch kind {
+			case 'B':
+				return Tag{Type: TAG_Byte_Array, Payload: bytesOut}, nil
+			case 'I':
+				return Tag{Type: TAG_Int_Array, Payload: intsOut}, nil
+			default:
+				return Tag{Type: TAG_List, Payload: TagList{Type: TAG_Long, Elems: longsOut}}, nil
+			}
+		default:
+			return Tag{}, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *snbtParser) parseList() (Tag, error) {
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return Tag{Type: TAG_List, Payload: TagList{Type: TAG_End, Elems: nil}}, nil
+	}
+
+	var ltt TagType
+	var elems []interface{}
+
+	for {
+		p.skipSpace()
+		tag, err := p.parseValue()
+		if err != nil {
+			return Tag{}, err
+		}
+		if elems == nil {
+			ltt = tag.Type
+		} else if tag.Type != ltt {
+			return Tag{}, p.errorf("list elements have mixed types %s and %s", ltt, tag.Type)
+		}
+		elems = append(elems, tag.Payload)
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case ']':
+			p.pos++
+			return Tag{Type: TAG_List, Payload: TagList{Type: ltt, Elems: elems}}, nil
+		default:
+			return Tag{}, p.errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+func (p *snbtParser) parseQuotedString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		if c == quote {
+			p.pos++
+			return sb.String(), nil
+		}
+		if c == '\\' {
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated escape sequence")
+			}
+			sb.WriteByte(p.src[p.pos])
+			p.pos++
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *snbtParser) parseBareValue() (Tag, error) {
+	start := p.pos
+	for !p.eof() && isBareValueByte(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return Tag{}, p.errorf("expected a value")
+	}
+	word := p.src[start:p.pos]
+
+	if word == "true" {
+		return Tag{Type: TAG_Byte, Payload: byte(1)}, nil
+	}
+	if word == "false" {
+		return Tag{Type: TAG_Byte, Payload: byte(0)}, nil
+	}
+
+	// A suffix letter only commits to the matching numeric type if the
+	// rest of the word actually parses as a number; otherwise the word is
+	// an ordinary bare string that happens to end in e.g. "d" ("world"),
+	// so it falls through to the plain-string case below, same as a
+	// no-suffix word that isn't a number.
+	last := word[len(word)-1]
+	switch last {
+	case 'b', 'B':
+		if n, err := strconv.ParseInt(word[:len(word)-1], 10, 8); err == nil {
+			return Tag{Type: TAG_Byte, Payload: byte(int8(n))}, nil
+		}
+	case 's', 'S':
+		if n, err := strconv.ParseInt(word[:len(word)-1], 10, 16); err == nil {
+			return Tag{Type: TAG_Short, Payload: int16(n)}, nil
+		}
+	case 'l', 'L':
+		if n, err := strconv.ParseInt(word[:len(word)-1], 10, 64); err == nil {
+			return Tag{Type: TAG_Long, Payload: n}, nil
+		}
+	case 'f', 'F':
+		if n, err := strconv.ParseFloat(word[:len(word)-1], 32); err == nil {
+			return Tag{Type: TAG_Float, Payload: float32(n)}, nil
+		}
+	case 'd', 'D':
+		if n, err := strconv.ParseFloat(word[:len(word)-1], 64); err == nil {
+			return Tag{Type: TAG_Double, Payload: n}, nil
+		}
+	}
+
+	if n, err := strconv.ParseInt(word, 10, 32); err == nil {
+		return Tag{Type: TAG_Int, Payload: int32(n)}, nil
+	}
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return Tag{Type: TAG_Double, Payload: n}, nil
+	}
+
+	return Tag{Type: TAG_String, Payload: word}, nil
+}
+
+func isBareValueByte(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b == '.' || b == '+' || b == '-'
+}
@@ -0,0 +1,78 @@
+package nbt
+
+import "testing"
+
+func TestUnmarshalSNBT(t *testing.T) {
+	tag, err := UnmarshalSNBT([]byte(`{foo:1b,bar:[I;1,2,3],baz:"hello \"world\"",list:[1,2,3]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalSNBT: %v", err)
+	}
+
+	comp, ok := tag.Payload.(TagCompound)
+	if !ok {
+		t.Fatalf("root tag is %s, not TAG_Compound", tag.Type)
+	}
+
+	if comp["foo"].Type != TAG_Byte || comp["foo"].Payload.(byte) != 1 {
+		t.Errorf("foo = %+v", comp["foo"])
+	}
+
+	intArr, ok := comp["bar"].Payload.([]int32)
+	if !ok || len(intArr) != 3 || intArr[1] != 2 {
+		t.Errorf("bar = %+v", comp["bar"])
+	}
+
+	if comp["baz"].Payload.(string) != `hello "world"` {
+		t.Errorf("baz = %q", comp["baz"].Payload)
+	}
+
+	list, ok := comp["list"].Payload.(TagList)
+	if !ok || len(list.Elems) != 3 || list.Type != TAG_Int {
+		t.Errorf("list = %+v", comp["list"])
+	}
+}
+
+func TestSNBTRoundtrip(t *testing.T) {
+	orig := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"name":  Tag{Type: TAG_String, Payload: "Steve"},
+		"value": Tag{Type: TAG_Double, Payload: 0.5},
+		"tags":  Tag{Type: TAG_List, Payload: TagList{Type: TAG_String, Elems: []interface{}{"a", "b"}}},
+	}}
+
+	text, err := MarshalSNBT(orig)
+	if err != nil {
+		t.Fatalf("MarshalSNBT: %v", err)
+	}
+
+	got, err := UnmarshalSNBT(text)
+	if err != nil {
+		t.Fatalf("UnmarshalSNBT(%s): %v", text, err)
+	}
+
+	comp := got.Payload.(TagCompound)
+	if comp["name"].Payload.(string) != "Steve" {
+		t.Errorf("name = %+v", comp["name"])
+	}
+	if comp["value"].Payload.(float64) != 0.5 {
+		t.Errorf("value = %+v", comp["value"])
+	}
+}
+
+func TestUnmarshalSNBTMixedListRejected(t *testing.T) {
+	if _, err := UnmarshalSNBT([]byte(`[1,"two"]`)); err == nil {
+		t.Error("expected an error for a list with mixed element types")
+	}
+}
+
+func TestUnmarshalSNBTBareWordEndingInSuffixLetter(t *testing.T) {
+	for _, word := range []string{"bed", "gold", "world", "seed", "build"} {
+		tag, err := UnmarshalSNBT([]byte(word))
+		if err != nil {
+			t.Errorf("UnmarshalSNBT(%q): %v", word, err)
+			continue
+		}
+		if tag.Type != TAG_String || tag.Payload.(string) != word {
+			t.Errorf("UnmarshalSNBT(%q) = %+v, want TAG_String %q", word, tag, word)
+		}
+	}
+}
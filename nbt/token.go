@@ -0,0 +1,418 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Token is implemented by StartCompound, EndCompound, StartList, EndList,
+// Value and RawArray, the events produced by TokenDecoder.Token. Callers
+// type-switch on the returned Token, mirroring encoding/xml.Token.
+type Token interface {
+	token()
+}
+
+// StartCompound marks the beginning of a TAG_Compound. Inside a list it
+// carries no name.
+type StartCompound struct {
+	Name string
+}
+
+// EndCompound marks the TAG_End terminating the most recently opened
+// TAG_Compound.
+type EndCompound struct{}
+
+// StartList marks the beginning of a TAG_List of Len elements of
+// ElemType. Inside a list it carries no name.
+type StartList struct {
+	Name     string
+	ElemType TagType
+	Len      int
+}
+
+// EndList marks that all Len elements declared by the matching
+// StartList have been consumed.
+type EndList struct{}
+
+// Value is a fully decoded scalar tag: TAG_Byte, Short, Int, Long,
+// Float, Double or String.
+type Value struct {
+	Name    string
+	Type    TagType
+	Payload interface{}
+}
+
+// RawArray marks a TAG_Byte_Array or TAG_Int_Array of Len elements
+// whose payload bytes, still encoded according to the decoder's Flavor,
+// can be streamed from R without being materialized into a Go slice. R
+// must be fully read (or the token skipped) before the next call to
+// Token. TokenDecoder refuses to produce a RawArray for a TAG_Int_Array
+// under a variable-width flavor (e.g. BedrockNetwork), since such a
+// flavor has no fixed per-element byte span to limit R to.
+type RawArray struct {
+	Name string
+	Type TagType
+	Len  int
+	R    io.Reader
+}
+
+func (StartCompound) token() {}
+func (EndCompound) token()   {}
+func (StartList) token()     {}
+func (EndList) token()       {}
+func (Value) token()         {}
+func (RawArray) token()      {}
+
+type decFrameKind int
+
+const (
+	decFrameCompound decFrameKind = iota
+	decFrameList
+)
+
+type decFrame struct {
+	kind      decFrameKind
+	elemType  TagType
+	remaining int
+}
+
+// TokenDecoder reads NBT as a flat stream of Tokens, so that large
+// compounds, lists and arrays can be scanned or skipped without
+// materializing a full Tag tree, mirroring xml.Decoder's pull API.
+type TokenDecoder struct {
+	r       io.Reader
+	flavor  Flavor
+	stack   []decFrame
+	pending io.Reader
+}
+
+// NewTokenDecoder returns a TokenDecoder reading Java Edition's
+// big-endian NBT from r.
+func NewTokenDecoder(r io.Reader) *TokenDecoder {
+	return NewTokenDecoderFlavor(r, JavaBigEndian)
+}
+
+// NewTokenDecoderFlavor returns a TokenDecoder reading NBT encoded
+// according to flavor from r.
+func NewTokenDecoderFlavor(r io.Reader, flavor Flavor) *TokenDecoder {
+	return &TokenDecoder{r: r, flavor: flavor}
+}
+
+func (d *TokenDecoder) drainPending() error {
+	if d.pending == nil {
+		return nil
+	}
+	_, err := io.Copy(io.Discard, d.pending)
+	d.pending = nil
+	return err
+}
+
+func (d *TokenDecoder) push(f decFrame) {
+	d.stack = append(d.stack, f)
+}
+
+func (d *TokenDecoder) top() *decFrame {
+	return &d.stack[len(d.stack)-1]
+}
+
+func (d *TokenDecoder) pop() {
+	d.stack = d.stack[:len(d.stack)-1]
+}
+
+// Token returns the next Token in the stream. At end of input it
+// returns io.EOF.
+func (d *TokenDecoder) Token() (Token, error) {
+	if err := d.drainPending(); err != nil {
+		return nil, err
+	}
+
+	if len(d.stack) > 0 && d.top().kind == decFrameList {
+		f := d.top()
+		if f.remaining == 0 {
+			d.pop()
+			return EndList{}, nil
+		}
+		f.remaining--
+		return d.readValue("", f.elemType)
+	}
+
+	tt, err := readByte(d.r)
+	if err != nil {
+		return nil, err
+	}
+
+	if TagType(tt) == TAG_End {
+		if len(d.stack) == 0 {
+			return nil, errors.New("nbt: unexpected TAG_End at top level")
+		}
+		d.pop()
+		return EndCompound{}, nil
+	}
+
+	_name, err := readTagData(d.r, TAG_String, d.flavor)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.readValue(_name.(string), TagType(tt))
+}
+
+func (d *TokenDecoder) readValue(name string, tt TagType) (Token, error) {
+	switch tt {
+	case TAG_Compound:
+		d.push(decFrame{kind: decFrameCompound})
+		return StartCompound{Name: name}, nil
+	case TAG_List:
+		_ltt, err := readByte(d.r)
+		if err != nil {
+			return nil, err
+		}
+		ltt := TagType(_ltt)
+
+		l, err := d.flavor.ReadArrayLen(d.r)
+		if err != nil {
+			return nil, err
+		}
+
+		d.push(decFrame{kind: decFrameList, elemType: ltt, remaining: l})
+		return StartList{Name: name, ElemType: ltt, Len: l}, nil
+	case TAG_Byte_Array:
+		l, err := d.flavor.ReadArrayLen(d.r)
+		if err != nil {
+			return nil, err
+		}
+		raw := io.LimitReader(d.r, int64(l))
+		d.pending = raw
+		return RawArray{Name: name, Type: tt, Len: l, R: raw}, nil
+	case TAG_Int_Array:
+		l, err := d.flavor.ReadArrayLen(d.r)
+		if err != nil {
+			return nil, err
+		}
+		elemSize, ok := d.flavor.(intArrayElemSizer)
+		if !ok {
+			return nil, fmt.Errorf("nbt: TokenDecoder cannot stream %s raw under a variable-width flavor; use ReadNamedTagFlavor instead", tt)
+		}
+		raw := io.LimitReader(d.r, int64(l)*int64(elemSize.intArrayElemSize()))
+		d.pending = raw
+		return RawArray{Name: name, Type: tt, Len: l, R: raw}, nil
+	default:
+		payload, err := readTagData(d.r, tt, d.flavor)
+		if err != nil {
+			return nil, err
+		}
+		return Value{Name: name, Type: tt, Payload: payload}, nil
+	}
+}
+
+// Skip discards the subtree opened by the most recently returned
+// StartCompound or StartList token, using only the declared list
+// lengths and compound terminators, without allocating for any array
+// or string payloads it passes over.
+func (d *TokenDecoder) Skip() error {
+	depth := 1
+	for depth > 0 {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case StartCompound, StartList:
+			depth++
+		case EndCompound, EndList:
+			depth--
+		}
+	}
+	return nil
+}
+
+type encFrameKind int
+
+const (
+	encFrameCompound encFrameKind = iota
+	encFrameList
+)
+
+type encFrame struct {
+	kind      encFrameKind
+	elemType  TagType
+	remaining int
+}
+
+// TokenEncoder writes NBT from a stream of WriteStart.../WriteEnd...
+// calls, validating that lists receive exactly as many elements as
+// declared and that every StartCompound/StartList is matched by the
+// corresponding End call.
+type TokenEncoder struct {
+	w      io.Writer
+	flavor Flavor
+	stack  []encFrame
+}
+
+// NewTokenEncoder returns a TokenEncoder writing Java Edition's
+// big-endian NBT to w.
+func NewTokenEncoder(w io.Writer) *TokenEncoder {
+	return NewTokenEncoderFlavor(w, JavaBigEndian)
+}
+
+// NewTokenEncoderFlavor returns a TokenEncoder writing NBT encoded
+// according to flavor to w.
+func NewTokenEncoderFlavor(w io.Writer, flavor Flavor) *TokenEncoder {
+	return &TokenEncoder{w: w, flavor: flavor}
+}
+
+// beforeValue reports whether a type byte and name must be written for
+// a value of type tt, and accounts for it against the enclosing list,
+// if any.
+func (e *TokenEncoder) beforeValue(tt TagType) (writeHeader bool, err error) {
+	if len(e.stack) == 0 {
+		return true, nil
+	}
+
+	top := &e.stack[len(e.stack)-1]
+	if top.kind != encFrameList {
+		return true, nil
+	}
+
+	if top.elemType != tt {
+		return false, fmt.Errorf("nbt: expected list element of type %s, got %s", top.elemType, tt)
+	}
+	if top.remaining == 0 {
+		return false, errors.New("nbt: too many elements written to list")
+	}
+	top.remaining--
+	return false, nil
+}
+
+func (e *TokenEncoder) writeHeader(tt TagType, name string) error {
+	if err := writeByte(e.w, byte(tt)); err != nil {
+		return err
+	}
+	return writeTagData(e.w, TAG_String, name, e.flavor)
+}
+
+// WriteStartCompound writes the start of a TAG_Compound called name.
+// name is ignored when writing a list element, which is unnamed.
+func (e *TokenEncoder) WriteStartCompound(name string) error {
+	writeHeader, err := e.beforeValue(TAG_Compound)
+	if err != nil {
+		return err
+	}
+	if writeHeader {
+		if err := e.writeHeader(TAG_Compound, name); err != nil {
+			return err
+		}
+	}
+	e.stack = append(e.stack, encFrame{kind: encFrameCompound})
+	return nil
+}
+
+// WriteEndCompound writes the TAG_End terminating the most recently
+// opened TAG_Compound.
+func (e *TokenEncoder) WriteEndCompound() error {
+	if len(e.stack) == 0 || e.stack[len(e.stack)-1].kind != encFrameCompound {
+		return errors.New("nbt: WriteEndCompound without a matching WriteStartCompound")
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	return writeByte(e.w, TAG_End)
+}
+
+// WriteStartList writes the start of a TAG_List called name, holding
+// length elements of elemType. name is ignored when writing a list
+// element.
+func (e *TokenEncoder) WriteStartList(name string, elemType TagType, length int) error {
+	writeHeader, err := e.beforeValue(TAG_List)
+	if err != nil {
+		return err
+	}
+	if writeHeader {
+		if err := e.writeHeader(TAG_List, name); err != nil {
+			return err
+		}
+	}
+
+	if err := writeByte(e.w, byte(elemType)); err != nil {
+		return err
+	}
+	if err := e.flavor.WriteArrayLen(e.w, length); err != nil {
+		return err
+	}
+
+	e.stack = append(e.stack, encFrame{kind: encFrameList, elemType: elemType, remaining: length})
+	return nil
+}
+
+// WriteEndList closes the most recently opened TAG_List. It is an error
+// to call it before exactly the declared number of elements have been
+// written.
+func (e *TokenEncoder) WriteEndList() error {
+	if len(e.stack) == 0 || e.stack[len(e.stack)-1].kind != encFrameList {
+		return errors.New("nbt: WriteEndList without a matching WriteStartList")
+	}
+	top := e.stack[len(e.stack)-1]
+	if top.remaining != 0 {
+		return fmt.Errorf("nbt: list closed with %d elements still undeclared", top.remaining)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	return nil
+}
+
+// WriteValue writes a scalar tag (TAG_Byte, Short, Int, Long, Float,
+// Double or String) called name with the given payload.
+func (e *TokenEncoder) WriteValue(name string, tt TagType, payload interface{}) error {
+	switch tt {
+	case TAG_Compound, TAG_List:
+		return fmt.Errorf("nbt: WriteValue cannot write %s, use WriteStartCompound/WriteStartList instead", tt)
+	}
+
+	writeHeader, err := e.beforeValue(tt)
+	if err != nil {
+		return err
+	}
+	if writeHeader {
+		if err := e.writeHeader(tt, name); err != nil {
+			return err
+		}
+	}
+
+	return writeTagData(e.w, tt, payload, e.flavor)
+}
+
+// WriteRawArray writes a TAG_Byte_Array or TAG_Int_Array called name,
+// holding length elements, copying its already flavor-encoded payload
+// bytes from r without decoding them. TAG_Int_Array requires a
+// fixed-width flavor, since a variable-width flavor (e.g. BedrockNetwork)
+// has no constant per-element byte span to copy.
+func (e *TokenEncoder) WriteRawArray(name string, tt TagType, length int, r io.Reader) error {
+	var elemSize int64
+	switch tt {
+	case TAG_Byte_Array:
+		elemSize = 1
+	case TAG_Int_Array:
+		sizer, ok := e.flavor.(intArrayElemSizer)
+		if !ok {
+			return fmt.Errorf("nbt: WriteRawArray cannot write %s under a variable-width flavor; use WriteValue instead", tt)
+		}
+		elemSize = int64(sizer.intArrayElemSize())
+	default:
+		return fmt.Errorf("nbt: WriteRawArray cannot write %s", tt)
+	}
+
+	writeHeader, err := e.beforeValue(tt)
+	if err != nil {
+		return err
+	}
+	if writeHeader {
+		if err := e.writeHeader(tt, name); err != nil {
+			return err
+		}
+	}
+
+	if err := e.flavor.WriteArrayLen(e.w, length); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(e.w, r, int64(length)*elemSize)
+	return err
+}
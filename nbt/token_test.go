@@ -0,0 +1,179 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func buildTokenTestTag() Tag {
+	return Tag{Type: TAG_Compound, Payload: TagCompound{
+		"name": Tag{Type: TAG_String, Payload: "steve"},
+		"pos":  Tag{Type: TAG_List, Payload: TagList{Type: TAG_Double, Elems: []interface{}{1.0, 2.0, 3.0}}},
+		"tag":  Tag{Type: TAG_Compound, Payload: TagCompound{"hp": Tag{Type: TAG_Int, Payload: int32(20)}}},
+		"data": Tag{Type: TAG_Byte_Array, Payload: []byte{1, 2, 3, 4}},
+	}}
+}
+
+func TestTokenDecoderWalksTree(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNamedTag(&buf, "Level", buildTokenTestTag()); err != nil {
+		t.Fatalf("WriteNamedTag: %v", err)
+	}
+
+	dec := NewTokenDecoder(bytes.NewReader(buf.Bytes()))
+
+	tok, err := dec.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	sc, ok := tok.(StartCompound)
+	if !ok || sc.Name != "Level" {
+		t.Fatalf("first token = %#v, want StartCompound{Level}", tok)
+	}
+
+	var sawRawArray bool
+	depth := 1
+	for depth > 0 {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		switch v := tok.(type) {
+		case StartCompound, StartList:
+			depth++
+		case EndCompound, EndList:
+			depth--
+		case RawArray:
+			sawRawArray = true
+			data, err := io.ReadAll(v.R)
+			if err != nil {
+				t.Fatalf("reading RawArray: %v", err)
+			}
+			if !bytes.Equal(data, []byte{1, 2, 3, 4}) {
+				t.Errorf("RawArray data = %v", data)
+			}
+		}
+	}
+
+	if !sawRawArray {
+		t.Error("never saw the byte array's RawArray token")
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		t.Errorf("final Token error = %v, want io.EOF", err)
+	}
+}
+
+func TestTokenDecoderSkip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNamedTag(&buf, "Level", buildTokenTestTag()); err != nil {
+		t.Fatalf("WriteNamedTag: %v", err)
+	}
+
+	dec := NewTokenDecoder(bytes.NewReader(buf.Bytes()))
+
+	if _, err := dec.Token(); err != nil { // StartCompound "Level"
+		t.Fatalf("Token: %v", err)
+	}
+	if err := dec.Skip(); err != nil {
+		t.Fatalf("Skip: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Errorf("Token after Skip = %v, want io.EOF", err)
+	}
+}
+
+func TestTokenEncoderRoundtrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewTokenEncoder(&buf)
+
+	if err := enc.WriteStartCompound("Level"); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteValue("hp", TAG_Int, int32(20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteStartList("pos", TAG_Double, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteValue("", TAG_Double, 1.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteValue("", TAG_Double, 2.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteEndList(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteEndCompound(); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, name, err := ReadNamedTag(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadNamedTag: %v", err)
+	}
+	if name != "Level" {
+		t.Errorf("name = %q, want Level", name)
+	}
+	comp := tag.Payload.(TagCompound)
+	if comp["hp"].Payload.(int32) != 20 {
+		t.Errorf("hp = %v", comp["hp"].Payload)
+	}
+	pos := comp["pos"].Payload.(TagList)
+	if len(pos.Elems) != 2 || pos.Elems[1].(float64) != 2.0 {
+		t.Errorf("pos = %+v", pos)
+	}
+}
+
+func TestTokenDecoderRejectsIntArrayUnderVariableWidthFlavor(t *testing.T) {
+	var buf bytes.Buffer
+	tag := Tag{Type: TAG_Compound, Payload: TagCompound{
+		"ints": Tag{Type: TAG_Int_Array, Payload: []int32{1, 2, 3}},
+		"name": Tag{Type: TAG_String, Payload: "steve"},
+	}}
+	if err := WriteNamedTagFlavor(&buf, "Level", tag, BedrockNetwork); err != nil {
+		t.Fatalf("WriteNamedTagFlavor: %v", err)
+	}
+
+	dec := NewTokenDecoderFlavor(bytes.NewReader(buf.Bytes()), BedrockNetwork)
+
+	if _, err := dec.Token(); err != nil { // StartCompound "Level"
+		t.Fatalf("Token: %v", err)
+	}
+
+	// Field order is map iteration order, so "ints" may or may not be the
+	// first token; keep reading until we hit the expected error.
+	for i := 0; i < 2; i++ {
+		_, err := dec.Token()
+		if err != nil {
+			return
+		}
+	}
+	t.Error("expected an error decoding TAG_Int_Array as a RawArray under BedrockNetwork")
+}
+
+func TestTokenEncoderRejectsWriteRawIntArrayUnderVariableWidthFlavor(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewTokenEncoderFlavor(&buf, BedrockNetwork)
+
+	if err := enc.WriteRawArray("ints", TAG_Int_Array, 3, bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})); err == nil {
+		t.Error("expected an error writing a raw TAG_Int_Array under BedrockNetwork")
+	}
+}
+
+func TestTokenEncoderRejectsShortList(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewTokenEncoder(&buf)
+
+	if err := enc.WriteStartList("pos", TAG_Double, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteValue("", TAG_Double, 1.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteEndList(); err == nil {
+		t.Error("expected an error when closing a list before its declared length is reached")
+	}
+}
@@ -0,0 +1,312 @@
+// Package regionfile implements reading and writing of Minecraft's
+// Anvil region file format (.mca), which packs a 32x32 grid of chunks
+// into a single file using a sector-based directory and per-chunk
+// zlib/gzip compression.
+package regionfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/silvasur/gonbt/nbt"
+)
+
+const (
+	sectorSize   = 4096
+	headerSize   = 2 * sectorSize
+	chunksPerDim = 32
+
+	// maxSectors is the largest chunk size the sector directory can
+	// address: the sector count is packed into the low 8 bits of each
+	// uint32 offsets entry, alongside the sector offset in the high 24
+	// bits.
+	maxSectors = 255
+)
+
+// Compression identifies the per-chunk compression scheme stored in a
+// chunk's 5-byte header, as used by the region file format.
+type Compression byte
+
+const (
+	CompressionGzip         Compression = 1
+	CompressionZlib         Compression = 2
+	CompressionUncompressed Compression = 3
+)
+
+// Region represents an open .mca region file.
+type Region struct {
+	f       *os.File
+	offsets [chunksPerDim * chunksPerDim]uint32 // sector offset<<8 | sector count
+	stamps  [chunksPerDim * chunksPerDim]uint32
+
+	nextSector int64       // first never-allocated sector, for growing the file
+	free       []freeRange // sectors freed by overwritten/removed chunks, sorted by start
+}
+
+// freeRange is a run of sectors, starting at sector start, that has been
+// vacated by an overwritten or removed chunk and is available for reuse.
+type freeRange struct {
+	start int64
+	count int
+}
+
+// OpenRegion opens the region file at path, creating it if it does not
+// exist, and reads its sector directory.
+func OpenRegion(path string) (*Region, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Region{f: f, nextSector: headerSize / sectorSize}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if fi.Size() == 0 {
+		if err := r.writeHeader(); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return r, nil
+	}
+
+	if fi.Size() < headerSize {
+		f.Close()
+		return nil, errors.New("regionfile: file is smaller than the sector directory")
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(io.NewSectionReader(f, 0, headerSize), header); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	for i := 0; i < chunksPerDim*chunksPerDim; i++ {
+		r.offsets[i] = binary.BigEndian.Uint32(header[i*4 : i*4+4])
+		r.stamps[i] = binary.BigEndian.Uint32(header[sectorSize+i*4 : sectorSize+i*4+4])
+	}
+
+	r.rebuildFreeList(fi.Size() / sectorSize)
+
+	return r, nil
+}
+
+// rebuildFreeList reconstructs the free-sector list from the sector
+// directory, treating every sector between the header and totalSectors
+// that no chunk's offsets entry claims as free.
+func (r *Region) rebuildFreeList(totalSectors int64) {
+	used := make([]bool, totalSectors)
+	for i := range r.offsets {
+		entry := r.offsets[i]
+		if entry == 0 {
+			continue
+		}
+		start, count := int64(entry>>8), int(entry&0xff)
+		for s := start; s < start+int64(count) && s < totalSectors; s++ {
+			used[s] = true
+		}
+	}
+
+	r.nextSector = totalSectors
+	r.free = nil
+	for s := int64(headerSize / sectorSize); s < totalSectors; {
+		if used[s] {
+			s++
+			continue
+		}
+		start := s
+		for s < totalSectors && !used[s] {
+			s++
+		}
+		r.free = append(r.free, freeRange{start: start, count: int(s - start)})
+	}
+}
+
+// Close closes the underlying file.
+func (r *Region) Close() error {
+	return r.f.Close()
+}
+
+func chunkIndex(cx, cz int) (int, error) {
+	lx, lz := cx&(chunksPerDim-1), cz&(chunksPerDim-1)
+	if lx != cx || lz != cz {
+		return 0, fmt.Errorf("regionfile: chunk coordinates (%d, %d) are outside this region", cx, cz)
+	}
+	return lz*chunksPerDim + lx, nil
+}
+
+// allocSectors returns the sector offset of a run of n free sectors,
+// taking it from the free list (first fit) if one is large enough, or
+// else extending the file.
+func (r *Region) allocSectors(n int) int64 {
+	for i, fr := range r.free {
+		if fr.count < n {
+			continue
+		}
+		start := fr.start
+		if fr.count == n {
+			r.free = append(r.free[:i], r.free[i+1:]...)
+		} else {
+			r.free[i] = freeRange{start: fr.start + int64(n), count: fr.count - n}
+		}
+		return start
+	}
+
+	start := r.nextSector
+	r.nextSector += int64(n)
+	return start
+}
+
+// freeSectors returns the run of n sectors starting at start to the free
+// list, so a later WriteChunk can reuse them instead of growing the
+// file.
+func (r *Region) freeSectors(start int64, n int) {
+	r.free = append(r.free, freeRange{start: start, count: n})
+}
+
+func (r *Region) writeHeader() error {
+	header := make([]byte, headerSize)
+	for i := 0; i < chunksPerDim*chunksPerDim; i++ {
+		binary.BigEndian.PutUint32(header[i*4:i*4+4], r.offsets[i])
+		binary.BigEndian.PutUint32(header[sectorSize+i*4:sectorSize+i*4+4], r.stamps[i])
+	}
+	_, err := r.f.WriteAt(header, 0)
+	return err
+}
+
+// ReadChunk reads and decompresses the chunk at (cx, cz), given in
+// region-local chunk coordinates (0..31), and decodes it as NBT.
+func (r *Region) ReadChunk(cx, cz int) (nbt.TagCompound, error) {
+	idx, err := chunkIndex(cx, cz)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := r.offsets[idx]
+	if entry == 0 {
+		return nil, fmt.Errorf("regionfile: no chunk stored at (%d, %d)", cx, cz)
+	}
+
+	sectorOffset := int64(entry >> 8)
+
+	var lenAndComp [5]byte
+	if _, err := r.f.ReadAt(lenAndComp[:], sectorOffset*sectorSize); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(lenAndComp[:4])
+	if length == 0 {
+		return nil, fmt.Errorf("regionfile: chunk at (%d, %d) has zero length", cx, cz)
+	}
+	comp := Compression(lenAndComp[4])
+
+	data := make([]byte, length-1)
+	if _, err := r.f.ReadAt(data, sectorOffset*sectorSize+5); err != nil {
+		return nil, err
+	}
+
+	var dr io.Reader
+	switch comp {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		dr = gz
+	case CompressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		dr = zr
+	case CompressionUncompressed:
+		dr = bytes.NewReader(data)
+	default:
+		return nil, fmt.Errorf("regionfile: unknown compression type %d", comp)
+	}
+
+	tag, _, err := nbt.ReadNamedTag(dr)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := tag.Payload.(nbt.TagCompound)
+	if !ok {
+		return nil, errors.New("regionfile: chunk root tag is not a TAG_Compound")
+	}
+	return root, nil
+}
+
+// WriteChunk zlib-compresses root and writes it as the chunk at (cx, cz),
+// reusing the chunk's own previously-allocated sectors when the new data
+// still fits there, otherwise drawing from sectors freed by earlier
+// overwrites or, failing that, growing the file, and rewrites the
+// sector directory.
+func (r *Region) WriteChunk(cx, cz int, root nbt.TagCompound) error {
+	idx, err := chunkIndex(cx, cz)
+	if err != nil {
+		return err
+	}
+
+	var payload bytes.Buffer
+	zw := zlib.NewWriter(&payload)
+	if err := nbt.WriteNamedTag(zw, "", nbt.Tag{Type: nbt.TAG_Compound, Payload: root}); err != nil {
+		zw.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	chunkData := make([]byte, 5+payload.Len())
+	binary.BigEndian.PutUint32(chunkData[:4], uint32(payload.Len()+1))
+	chunkData[4] = byte(CompressionZlib)
+	copy(chunkData[5:], payload.Bytes())
+
+	sectors := (len(chunkData) + sectorSize - 1) / sectorSize
+	if sectors > maxSectors {
+		return fmt.Errorf("regionfile: chunk at (%d, %d) needs %d sectors, more than the %d the directory can address", cx, cz, sectors, maxSectors)
+	}
+	paddedLen := sectors * sectorSize
+
+	oldEntry := r.offsets[idx]
+	oldOffset, oldSectors := int64(oldEntry>>8), int(oldEntry&0xff)
+
+	var sectorOffset int64
+	switch {
+	case oldEntry == 0:
+		sectorOffset = r.allocSectors(sectors)
+	case sectors <= oldSectors:
+		sectorOffset = oldOffset
+		if sectors < oldSectors {
+			r.freeSectors(oldOffset+int64(sectors), oldSectors-sectors)
+		}
+	default:
+		r.freeSectors(oldOffset, oldSectors)
+		sectorOffset = r.allocSectors(sectors)
+	}
+
+	buf := make([]byte, paddedLen)
+	copy(buf, chunkData)
+	if _, err := r.f.WriteAt(buf, sectorOffset*sectorSize); err != nil {
+		return err
+	}
+
+	r.offsets[idx] = uint32(sectorOffset)<<8 | uint32(sectors)
+	r.stamps[idx] = uint32(time.Now().Unix())
+
+	return r.writeHeader()
+}
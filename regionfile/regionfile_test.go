@@ -0,0 +1,130 @@
+package regionfile
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/silvasur/gonbt/nbt"
+)
+
+func openTestRegion(t *testing.T) *Region {
+	t.Helper()
+	r, err := OpenRegion(filepath.Join(t.TempDir(), "r.0.0.mca"))
+	if err != nil {
+		t.Fatalf("OpenRegion: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestWriteReadChunkRoundtrip(t *testing.T) {
+	r := openTestRegion(t)
+
+	root := nbt.TagCompound{"foo": nbt.Tag{Type: nbt.TAG_String, Payload: "bar"}}
+	if err := r.WriteChunk(1, 2, root); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	got, err := r.ReadChunk(1, 2)
+	if err != nil {
+		t.Fatalf("ReadChunk: %v", err)
+	}
+	if got["foo"].Payload.(string) != "bar" {
+		t.Errorf("ReadChunk = %+v", got)
+	}
+}
+
+func TestWriteChunkReusesOwnSectorsOnRewrite(t *testing.T) {
+	r := openTestRegion(t)
+
+	root := nbt.TagCompound{"foo": nbt.Tag{Type: nbt.TAG_String, Payload: "bar"}}
+	if err := r.WriteChunk(3, 4, root); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	fi, err := r.f.Stat()
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	size := fi.Size()
+
+	for i := 0; i < 4; i++ {
+		if err := r.WriteChunk(3, 4, root); err != nil {
+			t.Fatalf("WriteChunk (rewrite %d): %v", i, err)
+		}
+	}
+
+	fi, err = r.f.Stat()
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Size() != size {
+		t.Errorf("file size after repeated rewrites = %d, want unchanged %d", fi.Size(), size)
+	}
+
+	got, err := r.ReadChunk(3, 4)
+	if err != nil {
+		t.Fatalf("ReadChunk: %v", err)
+	}
+	if got["foo"].Payload.(string) != "bar" {
+		t.Errorf("ReadChunk = %+v", got)
+	}
+}
+
+func TestWriteChunkReusesFreedSectorsForAnotherChunk(t *testing.T) {
+	r := openTestRegion(t)
+
+	big := make(nbt.TagCompound)
+	data := make([]byte, 3*sectorSize)
+	rand.New(rand.NewSource(2)).Read(data)
+	big["data"] = nbt.Tag{Type: nbt.TAG_Byte_Array, Payload: data}
+	if err := r.WriteChunk(0, 0, big); err != nil {
+		t.Fatalf("WriteChunk big: %v", err)
+	}
+
+	small := nbt.TagCompound{"foo": nbt.Tag{Type: nbt.TAG_String, Payload: "bar"}}
+	if err := r.WriteChunk(0, 0, small); err != nil {
+		t.Fatalf("WriteChunk shrink: %v", err)
+	}
+
+	fi, err := r.f.Stat()
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	sizeBeforeReuse := fi.Size()
+
+	if err := r.WriteChunk(1, 1, small); err != nil {
+		t.Fatalf("WriteChunk reuse: %v", err)
+	}
+
+	fi, err = r.f.Stat()
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Size() != sizeBeforeReuse {
+		t.Errorf("file size after writing into freed sectors = %d, want unchanged %d", fi.Size(), sizeBeforeReuse)
+	}
+
+	got, err := r.ReadChunk(1, 1)
+	if err != nil {
+		t.Fatalf("ReadChunk: %v", err)
+	}
+	if got["foo"].Payload.(string) != "bar" {
+		t.Errorf("ReadChunk = %+v", got)
+	}
+}
+
+func TestWriteChunkRejectsOversizedChunk(t *testing.T) {
+	r := openTestRegion(t)
+
+	// Incompressible data, so zlib can't shrink the chunk back under the
+	// directory's sector limit.
+	data := make([]byte, maxSectors*sectorSize)
+	rand.New(rand.NewSource(1)).Read(data)
+	root := nbt.TagCompound{"data": nbt.Tag{Type: nbt.TAG_Byte_Array, Payload: data}}
+
+	if err := r.WriteChunk(0, 0, root); err == nil {
+		t.Error("expected an error for a chunk exceeding the directory's addressable sector count")
+	}
+}